@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/lighttiger2505/s3tf/model"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// addBookmark asks name and bookmarks the location shown on the active pane.
+func (p *Provider) addBookmark() {
+	loc := p.Location()
+	name := loc.Bucket
+	if loc.Key != "" {
+		name = path.Base(loc.Key)
+	}
+	p.prompt("bookmark name: ", name, func(name string) {
+		if name == "" {
+			p.statusView.Msg = "abort bookmark"
+			return
+		}
+		p.bookmarks.Add(&model.Bookmark{Name: name, Location: *loc})
+		if p.saveBookmarks() {
+			p.statusView.Msg = fmt.Sprintf("bookmark %s. %s", name, loc)
+		}
+	})
+}
+
+func (p *Provider) saveBookmarks() bool {
+	p.bookmarkView.Update(p.bookmarks.Items)
+	if err := model.SaveBookmarkFile(p.bookmarks); err != nil {
+		p.statusView.Msg = fmt.Sprintf("failed save bookmarks, %v", err)
+		return false
+	}
+	return true
+}
+
+func (p *Provider) openBookmarks() {
+	p.status = StateBookmark
+	p.bookmarkView.Update(p.bookmarks.Items)
+}
+
+// jumpBookmark opens location of bookmark.
+func (p *Provider) jumpBookmark(b *model.Bookmark) {
+	p.status = StateList
+	p.pushNavigation()
+	p.showLocation(&b.Location)
+	p.statusView.Msg = fmt.Sprintf("jump bookmark %s. %s", b.Name, b.Location.String())
+}
+
+func (p *Provider) deleteBookmark(b *model.Bookmark) {
+	p.prompt(fmt.Sprintf("delete bookmark %s? (y/n): ", b.Name), "", func(answer string) {
+		if answer != "y" {
+			return
+		}
+		p.bookmarks.Remove(b.Name)
+		if p.saveBookmarks() {
+			p.statusView.Msg = fmt.Sprintf("delete bookmark. %s", b.Name)
+		}
+	})
+}
+
+// importBookmarks merges bookmarks shared as file. Same names are overwritten.
+func (p *Provider) importBookmarks() {
+	p.prompt("import bookmarks from: ", "", func(path string) {
+		if path == "" {
+			return
+		}
+		f, err := model.ReadBookmarkFile(path)
+		if err != nil {
+			p.statusView.Msg = fmt.Sprintf("failed import bookmarks, %v", err)
+			return
+		}
+		p.bookmarks.Merge(f)
+		if p.saveBookmarks() {
+			p.statusView.Msg = fmt.Sprintf("import %d bookmarks. %s", len(f.Items), path)
+		}
+	})
+}
+
+func (p *Provider) exportBookmarks() {
+	p.prompt("export bookmarks to: ", "bookmarks.yml", func(path string) {
+		if path == "" {
+			return
+		}
+		if err := model.WriteBookmarkFile(path, p.bookmarks); err != nil {
+			p.statusView.Msg = fmt.Sprintf("failed export bookmarks, %v", err)
+			return
+		}
+		p.statusView.Msg = fmt.Sprintf("export %d bookmarks. %s", len(p.bookmarks.Items), path)
+	})
+}
+
+func (p *Provider) bookmarkEvent(ev termbox.Event) {
+	ea := getEventAction(ev, chMapOnBookmark, keyMapOnBookmark)
+	if ea == "" {
+		p.statusView.Msg = "no mapping key"
+		return
+	}
+
+	switch ea {
+	case actQuit:
+		p.status = StateList
+	case actUp:
+		p.bookmarkView.Up()
+	case actDown:
+		p.bookmarkView.Down()
+	case actHalfUp:
+		p.bookmarkView.HalfPageUp()
+	case actHalfDown:
+		p.bookmarkView.HalfPageDown()
+	case actAddBookmark:
+		p.addBookmark()
+	case actImportBookmarks:
+		p.importBookmarks()
+	case actExportBookmarks:
+		p.exportBookmarks()
+	case actJumpBookmark, actDeleteBookmark:
+		b := p.bookmarkView.GetCursorObject()
+		if b == nil {
+			p.statusView.Msg = "no bookmark"
+			return
+		}
+		if ea == actJumpBookmark {
+			p.jumpBookmark(b)
+		} else {
+			p.deleteBookmark(b)
+		}
+	default:
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/lighttiger2505/s3tf/model"
+	"github.com/lighttiger2505/s3tf/view"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// configReview is bucket configuration edited and waiting for confirmation to put.
+type configReview struct {
+	bucket  string
+	kind    model.BucketConfig
+	current []byte
+	edited  []byte
+	tempDir string
+}
+
+func (r *configReview) path() string {
+	return filepath.Join(r.tempDir, r.kind.Filename())
+}
+
+func (r *configReview) close() {
+	os.RemoveAll(r.tempDir)
+}
+
+// configBucket returns bucket of the cursor entry on bucket list, or the current bucket.
+func (p *Provider) configBucket() string {
+	if p.node.IsRoot() {
+		obj := p.listView.GetCursorObject()
+		if obj.ObjType != model.Bucket {
+			return ""
+		}
+		return obj.Name
+	}
+	return p.bucket
+}
+
+// editBucketConfig asks kind of configuration and opens it by editor.
+func (p *Provider) editBucketConfig() {
+	bucket := p.configBucket()
+	if bucket == "" {
+		p.statusView.Msg = "select bucket to edit configuration"
+		return
+	}
+	p.prompt(fmt.Sprintf("configuration of %s. (p)olicy, (l)ifecycle or (c)ors: ", bucket), "", func(answer string) {
+		var kind model.BucketConfig
+		switch answer {
+		case "p", "policy":
+			kind = model.ConfigPolicy
+		case "l", "lifecycle":
+			kind = model.ConfigLifecycle
+		case "c", "cors":
+			kind = model.ConfigCORS
+		default:
+			p.statusView.Msg = "abort edit configuration"
+			return
+		}
+
+		current, err := model.GetBucketConfig(bucket, kind)
+		if err != nil {
+			p.statusView.Msg = err.Error()
+			return
+		}
+		tempDir, err := ioutil.TempDir("", "s3tf")
+		if err != nil {
+			p.statusView.Msg = err.Error()
+			return
+		}
+		r := &configReview{bucket: bucket, kind: kind, current: current, tempDir: tempDir}
+		if err := ioutil.WriteFile(r.path(), current, 0600); err != nil {
+			r.close()
+			p.statusView.Msg = err.Error()
+			return
+		}
+		p.editConfigFile(r)
+	})
+}
+
+// editConfigFile opens editor, validates the edited configuration and shows diff to confirm.
+func (p *Provider) editConfigFile(r *configReview) {
+	termbox.Close()
+	OpenEditor(r.path())
+	termbox.Init()
+	p.status = StateList
+
+	b, err := ioutil.ReadFile(r.path())
+	if err != nil {
+		r.close()
+		p.statusView.Msg = err.Error()
+		return
+	}
+	edited, err := model.NormalizeBucketConfig(r.kind, b)
+	if err != nil {
+		p.prompt(fmt.Sprintf("%v. (e)dit again or (a)bort: ", err), "", func(answer string) {
+			if answer == "e" || answer == "edit" {
+				p.editConfigFile(r)
+				return
+			}
+			r.close()
+			p.statusView.Msg = "abort edit configuration"
+		})
+		return
+	}
+	if bytes.Equal(edited, r.current) {
+		r.close()
+		p.statusView.Msg = "no changes"
+		return
+	}
+	r.edited = b
+
+	currentPath := filepath.Join(r.tempDir, "current")
+	editedPath := filepath.Join(r.tempDir, "edited")
+	if err := ioutil.WriteFile(currentPath, r.current, 0600); err != nil {
+		r.close()
+		p.statusView.Msg = err.Error()
+		return
+	}
+	if err := ioutil.WriteFile(editedPath, edited, 0600); err != nil {
+		r.close()
+		p.statusView.Msg = err.Error()
+		return
+	}
+	diff, err := DiffFile(currentPath, editedPath)
+	if err != nil {
+		r.close()
+		p.statusView.Msg = err.Error()
+		return
+	}
+
+	var warnings []string
+	if r.kind == model.ConfigPolicy {
+		doc, _ := model.ParseBucketConfig(r.kind, b)
+		for _, name := range doc.(*model.PolicyDoc).PublicStatements() {
+			warnings = append(warnings, fmt.Sprintf("statement %s allows access to anyone by Principal \"*\"", name))
+		}
+	}
+	p.viewerView.SetDocument(view.RenderDiff(diff, warnings))
+	p.review = r
+	p.status = StateReview
+	p.statusView.Msg = fmt.Sprintf("put %s of %s? (y)es, (e)dit again or (q)uit", r.kind, r.bucket)
+}
+
+// applyReview puts reviewed configuration to bucket.
+func (p *Provider) applyReview() {
+	r := p.review
+	p.review = nil
+	p.status = StateList
+	defer r.close()
+	if err := model.PutBucketConfig(r.bucket, r.kind, r.edited); err != nil {
+		p.statusView.Msg = err.Error()
+		return
+	}
+	p.statusView.Msg = fmt.Sprintf("put %s. %s", r.kind, r.bucket)
+}
+
+func (p *Provider) reviewEvent(ev termbox.Event) {
+	ea := getEventAction(ev, chMapOnReview, keyMapOnViewer)
+	if ea == "" {
+		p.statusView.Msg = "no mapping key"
+		return
+	}
+
+	switch ea {
+	case actQuit:
+		p.review.close()
+		p.review = nil
+		p.status = StateList
+		p.statusView.Msg = "abort edit configuration"
+	case actApply:
+		p.applyReview()
+	case actEditAgain:
+		p.editConfigFile(p.review)
+	case actUp:
+		p.viewerView.Up()
+	case actDown:
+		p.viewerView.Down()
+	case actHalfUp:
+		p.viewerView.HalfPageUp()
+	case actHalfDown:
+		p.viewerView.HalfPageDown()
+	case actLeft:
+		p.viewerView.Left()
+	case actRight:
+		p.viewerView.Right()
+	default:
+	}
+}
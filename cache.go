@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/lighttiger2505/s3tf/model"
+)
+
+// revalidate lists node again in background when its listing is stale, keeping the stale
+// listing shown until then. Failed node is not retried until it is reloaded by hand.
+func (p *Provider) revalidate(lister model.Lister, bucket string, node *model.Node) {
+	if !node.IsStale(p.cacheTTL) || p.revalidating[node] {
+		return
+	}
+	p.revalidating[node] = true
+	go func() {
+		objects, err := model.Relist(lister, bucket, node)
+		p.post(func() {
+			if err != nil {
+				p.statusView.Msg = fmt.Sprintf("failed revalidate listing, %v", err)
+				return
+			}
+			delete(p.revalidating, node)
+			if p.node == node {
+				node.Position = p.listView.Cursor()
+			}
+			node.Refresh(objects)
+			if p.node == node {
+				p.listView.UpdateList(node)
+			}
+			if p.other != nil && p.other.node == node {
+				p.other.listView.UpdateList(node)
+			}
+		})
+	}()
+}
+
+// invalidateKey marks listings changed by our own write of key stale on every pane and tab
+// showing the same storage, to be revalidated when they are shown.
+func (p *Provider) invalidateKey(local bool, profile, bucket, key string) {
+	panes := append([]*pane{p.activePane()}, p.tabs...)
+	if p.other != nil {
+		panes = append(panes, p.other)
+	}
+	for _, pn := range panes {
+		if pn.lister.IsLocal() != local || (!local && pn.profile != profile) {
+			continue
+		}
+		pn.node.Root().InvalidateKey(bucket, key)
+	}
+}
+
+// invalidateS3Key marks listings changed by writing key in bucket of the current profile stale.
+func (p *Provider) invalidateS3Key(bucket, key string) {
+	p.invalidateKey(false, p.profile, bucket, key)
+}
+
+// invalidateTransfer marks listings changed by finished transfer stale.
+func (p *Provider) invalidateTransfer(t *model.Transfer) {
+	dst := t.Dst
+	p.invalidateKey(dst.Local, dst.Profile, dst.Bucket, dst.Key)
+	if t.Move {
+		src := t.Src
+		p.invalidateKey(src.Local, src.Profile, src.Bucket, src.Key)
+	}
+}
+
+// newS3Lister returns lister of profile backed by the disk cache of listings.
+func (p *Provider) newS3Lister(profile string) model.Lister {
+	return model.NewCacheLister(profile, p.listingCache)
+}
+
+func (p *Provider) setPreferCache(prefer bool) {
+	if l, ok := p.lister.(*model.CacheLister); ok {
+		l.PreferCache = prefer
+	}
+}
+
+// markCached marks nodes of the tree listed from disk cache.
+func (p *Provider) markCached(lister model.Lister, node *model.Node) {
+	if l, ok := lister.(*model.CacheLister); ok {
+		l.MarkCached(node.Root())
+	}
+}
+
+// isOnline reports whether S3 can be reached. Offline only cached listings can be browsed.
+func (p *Provider) isOnline() bool {
+	if l, ok := p.lister.(*model.CacheLister); ok && l.Offline() {
+		p.statusView.Msg = "offline. only cached listings can be browsed"
+		return false
+	}
+	return true
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/lighttiger2505/s3tf/model"
+	"github.com/urfave/cli"
+)
+
+const timeFormat = "2006-01-02 15:04:05"
+
+var outputFlag = cli.StringFlag{
+	Name:  "output, o",
+	Usage: "Output format (table, json or csv)",
+}
+
+func outputFormat(c *cli.Context) (model.OutputFormat, bool, error) {
+	if !c.IsSet("output") {
+		return "", false, nil
+	}
+	format, err := model.ParseOutputFormat(c.String("output"))
+	return format, true, err
+}
+
+func newCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:      "ls",
+			Usage:     "List buckets, or directories and objects under prefix",
+			ArgsUsage: "[s3://bucket/prefix]",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "long, l", Usage: "Show date and size"},
+				cli.BoolFlag{Name: "recursive, r", Usage: "List all objects under prefix"},
+				outputFlag,
+			},
+			Action: lsCommand,
+		},
+		{
+			Name:      "cat",
+			Usage:     "Write object contents to stdout",
+			ArgsUsage: "s3://bucket/key",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "decompress, d", Usage: "Decompress gzip or zstd compressed object"},
+			},
+			Action: catCommand,
+		},
+		{
+			Name:      "cp",
+			Usage:     "Copy between local files and objects",
+			ArgsUsage: "<src> <dst>",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "recursive, r", Usage: "Copy all files under directory or prefix"},
+			},
+			Action: cpCommand,
+		},
+		{
+			Name:      "rm",
+			Usage:     "Delete object",
+			ArgsUsage: "s3://bucket/key",
+			Flags: []cli.Flag{
+				cli.BoolFlag{Name: "recursive, r", Usage: "Delete all objects under prefix"},
+			},
+			Action: rmCommand,
+		},
+		{
+			Name:      "stat",
+			Usage:     "Show properties and tags of objects",
+			ArgsUsage: "s3://bucket/key...",
+			Flags:     []cli.Flag{outputFlag},
+			Action:    statCommand,
+		},
+	}
+}
+
+func lsCommand(c *cli.Context) error {
+	format, formatted, err := outputFormat(c)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	if c.NArg() == 0 {
+		buckets, err := model.FetchBuckets()
+		if err != nil {
+			return err
+		}
+		if formatted {
+			return model.WriteListing(w, format, buckets)
+		}
+		for _, b := range buckets {
+			printEntry(w, b, "", c.Bool("long"))
+		}
+		return nil
+	}
+
+	bucket, prefix, err := model.ParseS3URL(c.Args().First())
+	if err != nil {
+		return err
+	}
+	var objects []*model.S3Object
+	if c.Bool("recursive") {
+		objects, err = model.ListAllObjects(bucket, prefix)
+	} else {
+		objects, err = model.FetchObjects(bucket, prefix)
+	}
+	if err != nil {
+		return err
+	}
+	if formatted {
+		return model.WriteListing(w, format, objects)
+	}
+	// names are shown relative to the directory of prefix
+	dir := prefix[:strings.LastIndex(prefix, "/")+1]
+	for _, obj := range objects {
+		printEntry(w, obj, dir, c.Bool("long"))
+	}
+	return nil
+}
+
+func printEntry(w io.Writer, obj *model.S3Object, dir string, long bool) {
+	name := strings.TrimPrefix(obj.Name, dir)
+	if !long {
+		fmt.Fprintln(w, name)
+		return
+	}
+	switch obj.ObjType {
+	case model.Dir:
+		fmt.Fprintf(w, "%19s %12s %s\n", "", "PRE", name)
+	case model.Bucket:
+		fmt.Fprintf(w, "%19s %12s %s\n", obj.Date.Local().Format(timeFormat), "", name)
+	default:
+		fmt.Fprintf(w, "%19s %12d %s\n", obj.Date.Local().Format(timeFormat), *obj.Size, name)
+	}
+}
+
+func catCommand(c *cli.Context) error {
+	bucket, key, err := model.ParseS3URL(c.Args().First())
+	if err != nil {
+		return err
+	}
+	result, err := model.OpenObject(bucket, key)
+	if err != nil {
+		return err
+	}
+	defer result.Body.Close()
+
+	var r io.Reader = result.Body
+	if c.Bool("decompress") {
+		body := bufio.NewReader(result.Body)
+		head, _ := body.Peek(4)
+		encoding := model.DetectEncoding(key, aws.StringValue(result.ContentEncoding), head)
+		dr, err := model.NewDecoder(encoding, body)
+		if err != nil {
+			return err
+		}
+		defer dr.Close()
+		r = dr
+	}
+	_, err = io.Copy(os.Stdout, r)
+	return err
+}
+
+func cpCommand(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("usage: s3tf cp [-r] <src> <dst>")
+	}
+	profile := c.GlobalString("profile")
+	src, err := newEndpoint(c.Args()[0], profile)
+	if err != nil {
+		return err
+	}
+	dst, err := newEndpoint(c.Args()[1], profile)
+	if err != nil {
+		return err
+	}
+
+	recursive := c.Bool("recursive")
+	if recursive {
+		src.Key = dirKey(src.Key)
+		dst.Key = dirKey(dst.Key)
+	} else if dst.Key == "" || strings.HasSuffix(dst.Key, "/") {
+		dst.Key += path.Base(src.Key)
+	}
+
+	t := model.NewTransfer(src, dst, recursive, false)
+	if err := t.Execute(func(*model.Transfer) {}); err != nil {
+		return err
+	}
+	t.Status = model.TransferDone
+	fmt.Println(t)
+	return nil
+}
+
+// newEndpoint makes endpoint from s3:// URL or local path. Local directory path gets trailing slash.
+func newEndpoint(arg, profile string) (*model.Endpoint, error) {
+	if model.IsS3URL(arg) {
+		bucket, key, err := model.ParseS3URL(arg)
+		if err != nil {
+			return nil, err
+		}
+		return &model.Endpoint{Profile: profile, Bucket: bucket, Key: key}, nil
+	}
+
+	abs, err := filepath.Abs(arg)
+	if err != nil {
+		return nil, err
+	}
+	key := strings.TrimPrefix(filepath.ToSlash(abs), "/")
+	if info, err := os.Stat(abs); (err == nil && info.IsDir()) || strings.HasSuffix(arg, string(filepath.Separator)) {
+		key = dirKey(key)
+	}
+	return &model.Endpoint{Local: true, Bucket: model.LocalRoot, Key: key}, nil
+}
+
+func dirKey(key string) string {
+	if key == "" || strings.HasSuffix(key, "/") {
+		return key
+	}
+	return key + "/"
+}
+
+func rmCommand(c *cli.Context) error {
+	bucket, key, err := model.ParseS3URL(c.Args().First())
+	if err != nil {
+		return err
+	}
+	if c.Bool("recursive") {
+		return model.DeletePrefix(bucket, dirKey(key), func(key string) {
+			fmt.Printf("delete: %s\n", model.S3URL(bucket, key))
+		})
+	}
+	if key == "" {
+		return fmt.Errorf("key is required to delete object, use -r to delete all objects in bucket")
+	}
+	if err := model.Delete(bucket, key); err != nil {
+		return err
+	}
+	fmt.Printf("delete: %s\n", model.S3URL(bucket, key))
+	return nil
+}
+
+func statCommand(c *cli.Context) error {
+	format, _, err := outputFormat(c)
+	if err != nil {
+		return err
+	}
+	if c.NArg() == 0 {
+		return fmt.Errorf("usage: s3tf stat [-o format] s3://bucket/key...")
+	}
+	var stats []*model.ObjectStat
+	for _, arg := range c.Args() {
+		bucket, key, err := model.ParseS3URL(arg)
+		if err != nil {
+			return err
+		}
+		stat, err := model.Stat(bucket, key)
+		if err != nil {
+			return err
+		}
+		stats = append(stats, stat)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	return model.WriteStats(w, format, stats)
+}
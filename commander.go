@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/lighttiger2505/s3tf/model"
+	"github.com/lighttiger2505/s3tf/view"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// pane is location shown by a list. On commander mode the inactive one is
+// kept here and swapped with the location fields of Provider.
+type pane struct {
+	lister         model.Lister
+	profile        string
+	node           *model.Node
+	bucket         string
+	listView       *view.ListView
+	navigationView *view.NavigationView
+}
+
+func newLocalPane() *pane {
+	width, height := termbox.Size()
+	lister := model.NewLocalLister()
+	currentDir, _ := os.Getwd()
+	bucket, key := model.LocalKey(currentDir)
+	pn := &pane{
+		lister:         lister,
+		node:           model.NewNodeTree(lister, bucket, key),
+		bucket:         bucket,
+		listView:       view.NewListView(0, 1, width, height-2),
+		navigationView: view.NewNavigationView(0, 0, width, 1),
+	}
+	pn.listView.UpdateList(pn.node)
+	return pn
+}
+
+func (p *Provider) toggleCommander() {
+	p.commander = !p.commander
+	if p.other == nil {
+		p.other = newLocalPane()
+	}
+	p.activeLeft = true
+	p.previewKey = ""
+	p.navigationView.Inactive = false
+	if p.commander {
+		p.status = StateList
+	}
+}
+
+// switchPane activates the other pane.
+func (p *Provider) switchPane() {
+	if !p.commander {
+		return
+	}
+	other := p.other
+	p.other = p.activePane()
+	p.restorePane(other)
+	p.activeLeft = !p.activeLeft
+}
+
+// activePane returns location fields of Provider as pane.
+func (p *Provider) activePane() *pane {
+	return &pane{
+		lister:         p.lister,
+		profile:        p.profile,
+		node:           p.node,
+		bucket:         p.bucket,
+		listView:       p.listView,
+		navigationView: p.navigationView,
+	}
+}
+
+// restorePane sets location fields of Provider from pane.
+func (p *Provider) restorePane(pn *pane) {
+	p.lister = pn.lister
+	p.profile = pn.profile
+	p.node = pn.node
+	p.bucket = pn.bucket
+	p.listView = pn.listView
+	p.navigationView = pn.navigationView
+	p.previewKey = ""
+	model.SetProfile(p.profile)
+}
+
+func (p *Provider) resizePanes(width, top, height int) {
+	leftWidth := width / 2
+	activeX, activeWidth := 0, leftWidth
+	otherX, otherWidth := leftWidth, width-leftWidth
+	if !p.activeLeft {
+		activeX, activeWidth, otherX, otherWidth = otherX, otherWidth, activeX, activeWidth
+	}
+	p.listView.Layer.Resize(activeX, top+1, activeWidth-1, height)
+	p.navigationView.Win.Resize(activeX, top, activeWidth, 1)
+	p.other.listView.Layer.Resize(otherX, top+1, otherWidth-1, height)
+	p.other.navigationView.Win.Resize(otherX, top, otherWidth, 1)
+}
+
+func (p *Provider) updateOtherPane() {
+	other := p.other
+	p.navigationView.Inactive = false
+	other.navigationView.Inactive = true
+	if other.lister.IsLocal() {
+		other.navigationView.SetLocalPath(other.node)
+	} else {
+		other.navigationView.SetCurrentPath(other.bucket, other.node)
+	}
+	p.markCached(other.lister, other.node)
+	other.navigationView.Stale = other.node.IsStale(p.cacheTTL)
+	other.navigationView.CachedAt = other.node.CachedAt
+	p.revalidate(other.lister, other.bucket, other.node)
+}
+
+// setLocation shows bucket and key of lister on the active pane.
+// Key is a prefix, or an object to put cursor on.
+func (p *Provider) setLocation(lister model.Lister, bucket, key string) {
+	p.lister = lister
+	p.bucket = bucket
+	p.node = model.NewNodeTree(lister, bucket, key)
+	p.listView.ClearMarks()
+	p.listView.UpdateList(p.node)
+	p.previewKey = ""
+}
+
+// toggleLocal switches the active pane between S3 and current directory of local filesystem.
+func (p *Provider) toggleLocal() {
+	p.pushNavigation()
+	if p.lister.IsLocal() {
+		p.setLocation(p.newS3Lister(p.profile), "", "")
+		return
+	}
+	currentDir, _ := os.Getwd()
+	bucket, key := model.LocalKey(currentDir)
+	p.setLocation(model.NewLocalLister(), bucket, key)
+}
+
+func (p *Provider) switchProfile(profile string) {
+	p.profile = profile
+	model.SetProfile(profile)
+	if !p.lister.IsLocal() {
+		p.pushNavigation()
+		p.setLocation(p.newS3Lister(profile), "", "")
+	}
+	p.statusView.Msg = fmt.Sprintf("switch profile. %s", profile)
+}
+
+// transferToPane queues copy or move of the cursor object to the directory shown by the other pane.
+func (p *Provider) transferToPane(move bool) {
+	if !p.commander {
+		return
+	}
+	obj := p.listView.GetCursorObject()
+	if obj.ObjType != model.Object && obj.ObjType != model.Dir {
+		p.statusView.Msg = "select object or directory to transfer"
+		return
+	}
+	other := p.other
+	if other.node.IsRoot() {
+		p.statusView.Msg = "open destination bucket on the other pane"
+		return
+	}
+
+	name := path.Base(obj.Name)
+	if obj.ObjType == model.Dir {
+		name += "/"
+	}
+	src := &model.Endpoint{
+		Local:   p.lister.IsLocal(),
+		Profile: p.profile,
+		Bucket:  p.bucket,
+		Key:     obj.Name,
+	}
+	dst := &model.Endpoint{
+		Local:   other.lister.IsLocal(),
+		Profile: other.profile,
+		Bucket:  other.bucket,
+		Key:     other.node.Prefix() + name,
+	}
+	t := model.NewTransfer(src, dst, obj.ObjType == model.Dir, move)
+	p.statusView.Msg = t.String()
+	p.transferQueue.Add(t)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lighttiger2505/s3tf/model"
+	"github.com/lighttiger2505/s3tf/view"
+)
+
+// estimateCost shows monthly storage cost of marked directories, or the one on the cursor.
+// Sizes not calculated yet are calculated in background to estimate again.
+func (p *Provider) estimateCost() {
+	if p.lister.IsLocal() {
+		p.statusView.Msg = "cost is estimated only on S3"
+		return
+	}
+	targets := p.listView.MarkedObjects()
+	if len(targets) == 0 {
+		targets = []*model.S3Object{p.listView.GetCursorObject()}
+	}
+	total := model.NewDirUsage()
+	var names []string
+	var bucket string
+	pending := 0
+	for _, obj := range targets {
+		loc := p.usageLocation(obj)
+		if loc == nil {
+			continue
+		}
+		if bucket != "" && bucket != loc.Bucket {
+			p.statusView.Msg = "select directories in one bucket to estimate cost"
+			return
+		}
+		bucket = loc.Bucket
+		usage := p.usages.Get(loc)
+		if usage == nil {
+			p.startUsage(loc)
+			pending++
+			continue
+		}
+		total.Merge(usage)
+		names = append(names, loc.String())
+	}
+	if bucket == "" {
+		p.statusView.Msg = "select directory or bucket to estimate cost"
+		return
+	}
+	if pending > 0 {
+		p.statusView.Msg = fmt.Sprintf("calculating size of %d directories. press $ again when done", pending)
+		return
+	}
+
+	prices, err := model.LoadPriceTable()
+	if err != nil {
+		p.statusView.Msg = err.Error()
+		return
+	}
+	profile := p.profile
+	path := strings.Join(names, ", ")
+	key := profile + "/" + bucket
+	region, known := p.regions[key]
+	p.statusView.Msg = fmt.Sprintf("estimate cost. %s", path)
+	go func() {
+		var err error
+		if !known {
+			region, err = model.BucketRegion(profile, bucket)
+		}
+		var est *model.CostEstimate
+		if err == nil {
+			est, err = prices.Estimate(region, total)
+		}
+		p.post(func() {
+			if err != nil {
+				p.statusView.Msg = err.Error()
+				return
+			}
+			p.regions[key] = region
+			p.status = StateViewer
+			p.viewerView.SetDocument(view.RenderCost(path, total, est))
+			p.statusView.Msg = fmt.Sprintf("estimate cost. %s %.2f %s/month", path, est.Monthly, est.Currency)
+		})
+	}()
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lighttiger2505/s3tf/model"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// newFile asks name of new object under the current node, and uploads the file written by editor.
+func (p *Provider) newFile() {
+	if p.node.IsRoot() {
+		p.statusView.Msg = "open bucket to create file"
+		return
+	}
+	prefix := p.node.Prefix()
+	p.prompt("new file: "+prefix, "", func(name string) {
+		name = strings.TrimPrefix(name, "/")
+		if name == "" || strings.HasSuffix(name, "/") {
+			p.statusView.Msg = "invalid file name"
+			return
+		}
+		p.createFile(prefix + name)
+	})
+}
+
+func (p *Provider) createFile(key string) {
+	if p.lister.IsLocal() {
+		path := model.LocalPath(p.bucket, key)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			p.statusView.Msg = err.Error()
+			return
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err != nil {
+			p.statusView.Msg = err.Error()
+			return
+		}
+		f.Close()
+		termbox.Close()
+		err = OpenEditor(path)
+		termbox.Init()
+		p.reload()
+		if err != nil {
+			p.statusView.Msg = fmt.Sprintf("create %s, but failed editor, %v", path, err)
+			return
+		}
+		p.statusView.Msg = fmt.Sprintf("create. %s", path)
+		return
+	}
+
+	exists, err := model.Exists(p.bucket, key)
+	if err != nil {
+		p.statusView.Msg = err.Error()
+		return
+	}
+	if exists {
+		p.statusView.Msg = fmt.Sprintf("already exists. %s", key)
+		return
+	}
+
+	tempDir, err := ioutil.TempDir("", "s3tf")
+	if err != nil {
+		p.statusView.Msg = err.Error()
+		return
+	}
+	defer os.RemoveAll(tempDir)
+	path := filepath.Join(tempDir, model.Filename(key))
+	if err := ioutil.WriteFile(path, nil, 0600); err != nil {
+		p.statusView.Msg = err.Error()
+		return
+	}
+
+	termbox.Close()
+	err = OpenEditor(path)
+	termbox.Init()
+	if err != nil {
+		p.statusView.Msg = fmt.Sprintf("abort create, failed editor, %v", err)
+		return
+	}
+
+	if err := model.PutFile(p.bucket, key, path); err != nil {
+		p.statusView.Msg = err.Error()
+		return
+	}
+	prefix := p.node.Prefix()
+	if name := strings.TrimPrefix(key, prefix); strings.Contains(name, "/") {
+		// a nested name creates its top directory in the current listing
+		top := prefix + strings.SplitN(name, "/", 2)[0] + "/"
+		p.addObject(model.NewS3Object(model.Dir, top, nil, nil))
+		p.invalidateS3Key(p.bucket, key)
+	} else if info, err := os.Stat(path); err == nil {
+		modTime, size := info.ModTime(), info.Size()
+		p.addObject(model.NewS3Object(model.Object, key, &modTime, &size))
+	}
+	p.statusView.Msg = fmt.Sprintf("create. s3://%s/%s", p.bucket, key)
+}
+
+// makeDir asks name of new directory under the current node and creates it.
+func (p *Provider) makeDir() {
+	if p.node.IsRoot() {
+		p.statusView.Msg = "open bucket to create directory"
+		return
+	}
+	prefix := p.node.Prefix()
+	p.prompt("mkdir: "+prefix, "", func(name string) {
+		name = strings.Trim(name, "/")
+		if name == "" {
+			p.statusView.Msg = "invalid directory name"
+			return
+		}
+		key := prefix + name + "/"
+		if p.lister.IsLocal() {
+			if err := os.MkdirAll(model.LocalPath(p.bucket, key), 0755); err != nil {
+				p.statusView.Msg = err.Error()
+				return
+			}
+		} else if err := model.MakeDir(p.bucket, key); err != nil {
+			p.statusView.Msg = err.Error()
+			return
+		}
+		// a nested name creates its top directory in the current listing
+		top := prefix + strings.SplitN(name, "/", 2)[0] + "/"
+		p.addObject(model.NewS3Object(model.Dir, top, nil, nil))
+		p.statusView.Msg = fmt.Sprintf("mkdir. %s", key)
+	})
+}
+
+// addObject shows created object in the current listing and moves cursor to it.
+func (p *Provider) addObject(obj *model.S3Object) {
+	p.node.Position = p.node.AddObject(obj)
+	p.listView.UpdateList(p.node)
+	p.invalidateKey(p.lister.IsLocal(), p.profile, p.bucket, obj.Name)
+}
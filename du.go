@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/lighttiger2505/s3tf/model"
+	"github.com/lighttiger2505/s3tf/view"
+)
+
+// usageLocation returns location of Dir or Bucket entry on the active pane, nil for the others.
+func (p *Provider) usageLocation(obj *model.S3Object) *model.Location {
+	loc := &model.Location{Profile: p.profile, Bucket: p.bucket, Key: obj.Name}
+	switch obj.ObjType {
+	case model.Bucket:
+		loc.Bucket, loc.Key = obj.Name, ""
+	case model.Dir:
+	default:
+		return nil
+	}
+	if p.lister.IsLocal() {
+		loc.Profile, loc.Local = "", true
+	}
+	return loc
+}
+
+// calculateUsage calculates size of marked directories, or the one on the cursor.
+func (p *Provider) calculateUsage() {
+	targets := p.listView.MarkedObjects()
+	if len(targets) == 0 {
+		targets = []*model.S3Object{p.listView.GetCursorObject()}
+	}
+	started := 0
+	for _, obj := range targets {
+		if loc := p.usageLocation(obj); loc != nil && p.startUsage(loc) {
+			started++
+		}
+	}
+	if started == 0 {
+		p.statusView.Msg = "select directory or bucket to calculate size"
+	}
+}
+
+// startUsage calculates usage of loc in background. It returns false when it is already running.
+func (p *Provider) startUsage(loc *model.Location) bool {
+	if p.calculating[*loc] {
+		return false
+	}
+	p.calculating[*loc] = true
+	p.statusView.Msg = fmt.Sprintf("du. %s", loc)
+	go func() {
+		var usage *model.DirUsage
+		var err error
+		if loc.Local {
+			usage, err = model.CalculateLocalUsage(loc.Bucket, loc.Key)
+		} else {
+			usage, err = model.CalculateUsage(loc.Profile, loc.Bucket, loc.Key, func(u *model.DirUsage) {
+				msg := fmt.Sprintf("du. %s %s so far", loc, u)
+				p.post(func() {
+					p.statusView.Msg = msg
+				})
+			})
+		}
+		p.post(func() {
+			delete(p.calculating, *loc)
+			if err != nil {
+				// not to retry in background
+				p.usageFailed[*loc] = true
+				p.statusView.Msg = fmt.Sprintf("failed du %s, %v", loc, err)
+				return
+			}
+			delete(p.usageFailed, *loc)
+			p.usages.Put(loc, usage)
+			if err := model.SaveUsageCache(p.usages); err != nil {
+				p.statusView.Msg = fmt.Sprintf("failed save usage cache, %v", err)
+				return
+			}
+			p.statusView.Msg = fmt.Sprintf("du. %s %s", loc, usage)
+		})
+	}()
+	return true
+}
+
+// updateUsages shows calculated sizes of directories on the listing.
+// With background usage it starts calculation of the first directory not calculated yet.
+func (p *Provider) updateUsages() {
+	usages := map[string]*model.DirUsage{}
+	var next *model.Location
+	for _, obj := range p.node.Objects {
+		loc := p.usageLocation(obj)
+		if loc == nil {
+			continue
+		}
+		if usage := p.usages.Get(loc); usage != nil {
+			usages[obj.Name] = usage
+		} else if next == nil && !p.usageFailed[*loc] {
+			next = loc
+		}
+	}
+	p.listView.Usages = usages
+	if p.autoUsage && next != nil && len(p.calculating) == 0 {
+		p.startUsage(next)
+	}
+}
+
+// showUsage shows size of the directory broken down by storage class.
+func (p *Provider) showUsage(obj *model.S3Object) {
+	loc := p.usageLocation(obj)
+	usage := p.usages.Get(loc)
+	if usage == nil {
+		p.statusView.Msg = "size is not calculated. press U to calculate"
+		return
+	}
+	p.status = StateViewer
+	p.viewerView.SetDocument(view.RenderUsage(loc.String(), usage))
+	p.statusView.Msg = fmt.Sprintf("du. %s %s", loc, usage)
+}
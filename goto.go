@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lighttiger2505/s3tf/model"
+)
+
+// goTo asks path to jump, s3:// URL or path relative to the current node, with completion by tab.
+func (p *Provider) goTo() {
+	p.prompt("go to: ", "", p.jump)
+	p.promptComplete = p.completePath
+}
+
+// currentBucket returns bucket of the current node, empty on bucket list.
+func (p *Provider) currentBucket() string {
+	if p.node.IsRoot() {
+		return ""
+	}
+	return p.bucket
+}
+
+func (p *Provider) jump(path string) {
+	if path == "" {
+		return
+	}
+	p.pushNavigation()
+	if p.lister.IsLocal() && !model.IsS3URL(path) {
+		p.openPath(p.lister, model.LocalRoot, model.ResolveKey(p.node.Prefix(), path))
+		return
+	}
+
+	bucket, key, err := model.ResolvePath(p.currentBucket(), p.node.Prefix(), path)
+	if err != nil {
+		p.statusView.Msg = err.Error()
+		return
+	}
+	lister := p.lister
+	if lister.IsLocal() {
+		lister = p.newS3Lister(p.profile)
+	}
+	p.openPath(lister, bucket, key)
+}
+
+// openPath shows key in bucket, reusing visited nodes of the tree when lister is not changed.
+func (p *Provider) openPath(lister model.Lister, bucket, key string) {
+	if lister != p.lister {
+		p.setLocation(lister, bucket, key)
+	} else {
+		p.node = p.node.Root().Open(lister, bucket, key)
+		p.bucket = bucket
+		p.listView.UpdateList(p.node)
+		p.previewKey = ""
+	}
+	if bucket == "" {
+		p.statusView.Msg = "go to. bucket list"
+		return
+	}
+	p.statusView.Msg = fmt.Sprintf("go to. %s", p.Location())
+}
+
+// completePath completes the last element of path by directories and objects.
+// It returns completed path and candidates when they are ambiguous.
+func (p *Provider) completePath(path string) (string, []string) {
+	i := strings.LastIndex(path, "/")
+	head, partial := path[:i+1], path[i+1:]
+	if partial == "." || partial == ".." {
+		return path, nil
+	}
+
+	var names []string
+	if p.lister.IsLocal() && !model.IsS3URL(path) {
+		key := model.ResolveKey(p.node.Prefix(), head)
+		for _, obj := range p.lister.ListObjects(model.LocalRoot, key) {
+			if obj.ObjType != model.PreDir {
+				names = append(names, strings.TrimPrefix(obj.Name, key))
+			}
+		}
+	} else {
+		bucket, key, err := model.ResolvePath(p.currentBucket(), p.node.Prefix(), head)
+		if err != nil {
+			return path, []string{err.Error()}
+		}
+		if bucket == "" {
+			// buckets are already listed on root node of s3 pane
+			buckets := p.node.Root().Objects
+			if p.lister.IsLocal() {
+				buckets = p.newS3Lister(p.profile).ListBuckets()
+			}
+			for _, obj := range buckets {
+				names = append(names, obj.Name+"/")
+			}
+		} else {
+			objects, err := model.FetchObjects(bucket, key+partial)
+			if err != nil {
+				return path, []string{err.Error()}
+			}
+			for _, obj := range objects {
+				names = append(names, strings.TrimPrefix(obj.Name, key))
+			}
+		}
+	}
+
+	var candidates []string
+	for _, name := range names {
+		if strings.HasPrefix(name, partial) {
+			candidates = append(candidates, name)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return path, nil
+	case 1:
+		return head + candidates[0], nil
+	}
+	return head + commonPrefix(candidates), candidates
+}
+
+func commonPrefix(names []string) string {
+	prefix := names[0]
+	for _, name := range names[1:] {
+		for !strings.HasPrefix(name, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
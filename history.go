@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lighttiger2505/s3tf/model"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// showLocation opens loc, switching lister and profile when they differ.
+func (p *Provider) showLocation(loc *model.Location) {
+	lister := p.lister
+	if loc.Local {
+		if !lister.IsLocal() {
+			lister = model.NewLocalLister()
+		}
+	} else {
+		profile := loc.Profile
+		if profile == "" {
+			profile = p.profile
+		}
+		if lister.IsLocal() || profile != p.profile {
+			p.profile = profile
+			model.SetProfile(profile)
+			lister = p.newS3Lister(profile)
+		}
+	}
+	p.openPath(lister, loc.Bucket, loc.Key)
+}
+
+// pushNavigation records the current location before a jump to go back to it.
+func (p *Provider) pushNavigation() {
+	if p.node != nil {
+		p.navigation.Push(p.Location())
+	}
+}
+
+func (p *Provider) back() {
+	loc := p.navigation.Back(p.Location())
+	if loc == nil {
+		p.statusView.Msg = "no location to go back"
+		return
+	}
+	p.showLocation(loc)
+	p.statusView.Msg = fmt.Sprintf("back. %s", loc)
+}
+
+func (p *Provider) forward() {
+	loc := p.navigation.Forward(p.Location())
+	if loc == nil {
+		p.statusView.Msg = "no location to go forward"
+		return
+	}
+	p.showLocation(loc)
+	p.statusView.Msg = fmt.Sprintf("forward. %s", loc)
+}
+
+// recordVisit adds the current location to history when it has changed.
+func (p *Provider) recordVisit() {
+	loc := p.Location()
+	if p.visited != nil && *p.visited == *loc {
+		return
+	}
+	p.visited = loc
+	p.history.Visit(loc, time.Now())
+}
+
+// SaveHistory writes visited locations for later sessions.
+func (p *Provider) SaveHistory() error {
+	return model.SaveHistoryFile(p.history)
+}
+
+func (p *Provider) openHistory() {
+	p.status = StateHistory
+	p.updateHistoryView()
+}
+
+func (p *Provider) updateHistoryView() {
+	if p.historyView.Ranked {
+		p.historyView.Update(p.history.Ranked(time.Now()))
+		return
+	}
+	p.historyView.Update(p.history.Recent())
+}
+
+func (p *Provider) historyEvent(ev termbox.Event) {
+	ea := getEventAction(ev, chMapOnHistory, keyMapOnHistory)
+	if ea == "" {
+		p.statusView.Msg = "no mapping key"
+		return
+	}
+
+	switch ea {
+	case actQuit:
+		p.status = StateList
+	case actUp:
+		p.historyView.Up()
+	case actDown:
+		p.historyView.Down()
+	case actHalfUp:
+		p.historyView.HalfPageUp()
+	case actHalfDown:
+		p.historyView.HalfPageDown()
+	case actToggleRank:
+		p.historyView.Ranked = !p.historyView.Ranked
+		p.updateHistoryView()
+		if p.historyView.Ranked {
+			p.statusView.Msg = "history by frecency"
+		} else {
+			p.statusView.Msg = "history by recent"
+		}
+	case actJumpHistory:
+		v := p.historyView.GetCursorObject()
+		if v == nil {
+			return
+		}
+		p.status = StateList
+		p.pushNavigation()
+		p.showLocation(&v.Location)
+	case actDeleteHistory:
+		v := p.historyView.GetCursorObject()
+		if v == nil {
+			return
+		}
+		p.history.Remove(&v.Location)
+		p.updateHistoryView()
+	default:
+	}
+}
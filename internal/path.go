@@ -16,6 +16,20 @@ func GetXDGConfigPath() string {
 	return configdir
 }
 
+// GetXDGCachePath returns cache directory of s3tf, creating it when missing.
+func GetXDGCachePath() string {
+	cacheHome := os.Getenv("XDG_CACHE_HOME")
+	if cacheHome == "" {
+		home, _ := homedir.Dir()
+		cacheHome = filepath.Join(home, ".cache")
+	}
+	cachedir := filepath.Join(cacheHome, "s3tf")
+	if !IsFileExist(cachedir) {
+		os.MkdirAll(cachedir, os.FileMode(0755))
+	}
+	return cachedir
+}
+
 func IsFileExist(fPath string) bool {
 	_, err := os.Stat(fPath)
 	return err == nil || !os.IsNotExist(err)
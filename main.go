@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/lighttiger2505/s3tf/internal"
 	"github.com/lighttiger2505/s3tf/model"
@@ -33,7 +34,7 @@ func newApp() *cli.App {
 	app.Name = "s3 terminal finder"
 	app.HelpName = "s3tf"
 	app.Usage = "AWS S3 TUI file manager"
-	app.UsageText = "s3tf [options] <args>"
+	app.UsageText = "s3tf [options] [s3://bucket/prefix]"
 	app.Version = "0.0.1"
 	app.Author = "lighttiger2505"
 	app.Email = "lighttiger2505@gmail.com"
@@ -42,7 +43,43 @@ func newApp() *cli.App {
 			Name:  "mock, m",
 			Usage: "S3 api request to mock server on localhost(minio)",
 		},
+		cli.StringFlag{
+			Name:   "profile",
+			Usage:  "Use a specific profile from your credential file",
+			EnvVar: "AWS_PROFILE",
+		},
+		cli.BoolFlag{
+			Name:  "preview, p",
+			Usage: "Show preview pane of the object under the cursor",
+		},
+		cli.BoolFlag{
+			Name:  "miller",
+			Usage: "Show parent, current and preview columns side by side",
+		},
+		cli.BoolFlag{
+			Name:  "last",
+			Usage: "Reopen the location where the last session ended",
+		},
+		cli.DurationFlag{
+			Name:  "cache-ttl",
+			Value: 5 * time.Minute,
+			Usage: "Revalidate listings older than this in background, 0 to keep them until reload",
+		},
+		cli.BoolFlag{
+			Name:  "no-prefetch",
+			Usage: "Do not list directories around the cursor in background",
+		},
+		cli.BoolFlag{
+			Name:  "du",
+			Usage: "Calculate size of directories on listing in background",
+		},
 	}
+	app.Before = func(c *cli.Context) error {
+		model.MockFlag = c.Bool("mock")
+		model.SetProfile(c.String("profile"))
+		return nil
+	}
+	app.Commands = newCommands()
 	app.Action = run
 	return app
 }
@@ -62,15 +99,37 @@ func run(c *cli.Context) error {
 	defer logfile.Close()
 	log.SetOutput(io.MultiWriter(logfile))
 
-	// Set flags
-	model.MockFlag = c.Bool("mock")
+	var loc *model.Location
+	if c.NArg() > 0 {
+		if loc, err = model.ParseLocation(c.Args().First(), c.String("profile")); err != nil {
+			return err
+		}
+	} else if c.Bool("last") {
+		if loc, err = model.LoadLastLocation(); err != nil {
+			return err
+		}
+	}
 
 	if err := termbox.Init(); err != nil {
 		panic(err)
 	}
 	defer termbox.Close()
 
-	provider := NewProvider()
+	provider := NewProvider(&ProviderOption{
+		Profile:    c.String("profile"),
+		Preview:    c.Bool("preview"),
+		Miller:     c.Bool("miller"),
+		Location:   loc,
+		CacheTTL:   c.Duration("cache-ttl"),
+		NoPrefetch: c.Bool("no-prefetch"),
+		AutoUsage:  c.Bool("du"),
+	})
 	provider.Loop()
+	if err := model.SaveLastLocation(provider.Location()); err != nil {
+		log.Printf("failed save last location, %v", err)
+	}
+	if err := provider.SaveHistory(); err != nil {
+		log.Printf("failed save history, %v", err)
+	}
 	return nil
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+)
+
+// MergeFile merges changes from base to theirs into mine by diff3.
+// Conflicting changes are written with conflict markers, and reported by true.
+func MergeFile(mine, base, theirs string) (bool, error) {
+	if _, err := exec.LookPath("diff3"); err != nil {
+		return false, fmt.Errorf("diff3 is required to merge, %v", err)
+	}
+
+	out, err := exec.Command("diff3", "-m", "-L", "local", "-L", "base", "-L", "remote", mine, base, theirs).Output()
+	conflict := false
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		conflict = true
+	} else if err != nil {
+		return false, fmt.Errorf("failed merge, %v", err)
+	}
+
+	if err := ioutil.WriteFile(mine, out, 0600); err != nil {
+		return false, err
+	}
+	return conflict, nil
+}
+
+// DiffFile returns unified diff from current to edited.
+func DiffFile(current, edited string) (string, error) {
+	if _, err := exec.LookPath("diff"); err != nil {
+		return "", fmt.Errorf("diff is required to show changes, %v", err)
+	}
+
+	out, err := exec.Command("diff", "-u", "-L", "current", "-L", "edited", current, edited).Output()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return string(out), nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed diff, %v", err)
+	}
+	return string(out), nil
+}
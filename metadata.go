@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/lighttiger2505/s3tf/model"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// editProperties opens properties of the marked objects, or the cursor object, as YAML document by editor.
+// Marked objects get the same change made to the document of the first one.
+func (p *Provider) editProperties() {
+	var targets []string
+	for _, obj := range p.listView.MarkedObjects() {
+		if obj.ObjType == model.Object {
+			targets = append(targets, obj.Name)
+		}
+	}
+	if len(targets) == 0 {
+		obj := p.listView.GetCursorObject()
+		if obj.ObjType != model.Object {
+			p.statusView.Msg = "select object to edit properties"
+			return
+		}
+		targets = append(targets, obj.Name)
+	}
+
+	props, err := model.GetProperties(p.bucket, targets[0])
+	if err != nil {
+		p.statusView.Msg = err.Error()
+		return
+	}
+	before := props.Editable()
+	comments := []string{fmt.Sprintf("properties of s3://%s/%s", p.bucket, targets[0])}
+	if len(targets) > 1 {
+		comments = append(comments, fmt.Sprintf("changes are applied to %d marked objects", len(targets)))
+	}
+	b, err := model.MarshalEditable(before, comments...)
+	if err != nil {
+		p.statusView.Msg = err.Error()
+		return
+	}
+
+	tempDir, err := ioutil.TempDir("", "s3tf")
+	if err != nil {
+		p.statusView.Msg = err.Error()
+		return
+	}
+	path := filepath.Join(tempDir, "properties.yml")
+	if err := ioutil.WriteFile(path, b, 0600); err != nil {
+		os.RemoveAll(tempDir)
+		p.statusView.Msg = err.Error()
+		return
+	}
+	p.editPropertiesFile(path, before, p.bucket, targets)
+}
+
+func (p *Provider) editPropertiesFile(path string, before *model.EditableProperties, bucket string, targets []string) {
+	termbox.Close()
+	err := OpenEditor(path)
+	termbox.Init()
+	if err != nil {
+		os.RemoveAll(filepath.Dir(path))
+		p.statusView.Msg = fmt.Sprintf("abort edit properties, failed editor, %v", err)
+		return
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		os.RemoveAll(filepath.Dir(path))
+		p.statusView.Msg = err.Error()
+		return
+	}
+	after, err := model.ParseEditable(b)
+	if err != nil {
+		p.prompt(fmt.Sprintf("%v. (e)dit again or (a)bort: ", err), "", func(answer string) {
+			if answer == "e" || answer == "edit" {
+				p.editPropertiesFile(path, before, bucket, targets)
+				return
+			}
+			os.RemoveAll(filepath.Dir(path))
+			p.statusView.Msg = "abort edit properties"
+		})
+		return
+	}
+	os.RemoveAll(filepath.Dir(path))
+
+	diff := model.DiffEditable(before, after)
+	if diff.Empty() {
+		p.statusView.Msg = "no changes"
+		return
+	}
+	p.listView.ClearMarks()
+	p.statusView.Msg = fmt.Sprintf("updating properties of %d objects", len(targets))
+	go func() {
+		var failed []string
+		var lastErr error
+		for _, key := range targets {
+			if err := model.ApplyProperties(bucket, key, diff); err != nil {
+				failed = append(failed, key)
+				lastErr = err
+			}
+		}
+		p.post(func() {
+			if lastErr != nil {
+				p.statusView.Msg = fmt.Sprintf("failed update properties of %d objects, %v", len(failed), lastErr)
+				return
+			}
+			p.statusView.Msg = fmt.Sprintf("update properties of %d objects", len(targets))
+			p.previewKey = ""
+			for _, key := range targets {
+				p.invalidateS3Key(bucket, key)
+			}
+		})
+	}()
+}
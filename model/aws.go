@@ -1,9 +1,20 @@
 package model
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -17,18 +28,18 @@ import (
 
 var (
 	MockFlag bool
+	// profile is name of shared config profile used by package level functions.
+	profile string
 )
 
 const RequestTimeout time.Duration = time.Second * 30
 
 func ListBuckets() []*S3Object {
-	client := getS3Client()
-
-	ctx := context.Background()
-	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
-	defer cancelFn()
+	return listBuckets(getS3Client())
+}
 
-	result, err := client.ListBucketsWithContext(ctx, &s3.ListBucketsInput{})
+func listBuckets(client *s3.S3) []*S3Object {
+	objects, err := fetchBuckets(client)
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == request.CanceledErrorCode {
 			log.Fatalf("list buckets canceled due to timeout, %v", err)
@@ -36,6 +47,27 @@ func ListBuckets() []*S3Object {
 			log.Fatalf("failed list buckets, %v", err)
 		}
 	}
+	return objects
+}
+
+// FetchBuckets lists buckets, returning error instead of exiting.
+func FetchBuckets() ([]*S3Object, error) {
+	objects, err := fetchBuckets(getS3Client())
+	if err != nil {
+		return nil, fmt.Errorf("failed list buckets, %v", err)
+	}
+	return objects, nil
+}
+
+func fetchBuckets(client *s3.S3) ([]*S3Object, error) {
+	ctx := context.Background()
+	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
+	defer cancelFn()
+
+	result, err := client.ListBucketsWithContext(ctx, &s3.ListBucketsInput{})
+	if err != nil {
+		return nil, err
+	}
 
 	var objects []*S3Object
 	for _, bucket := range result.Buckets {
@@ -47,21 +79,15 @@ func ListBuckets() []*S3Object {
 		)
 		objects = append(objects, obj)
 	}
-	return objects
+	return objects, nil
 }
 
 func ListObjects(bucket, prefix string) []*S3Object {
-	client := getS3Client()
-
-	ctx := context.Background()
-	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
-	defer cancelFn()
+	return listObjects(getS3Client(), bucket, prefix)
+}
 
-	result, err := client.ListObjectsWithContext(ctx, &s3.ListObjectsInput{
-		Bucket:    aws.String(bucket),
-		Delimiter: aws.String("/"),
-		Prefix:    aws.String(prefix),
-	})
+func listObjects(client *s3.S3, bucket, prefix string) []*S3Object {
+	entries, err := fetchObjects(client, bucket, prefix)
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == request.CanceledErrorCode {
 			log.Fatalf("list objects canceled due to timeout, %v", err)
@@ -79,26 +105,55 @@ func ListObjects(bucket, prefix string) []*S3Object {
 		nil,
 	)
 	objects = append(objects, obj)
+	return append(objects, entries...)
+}
 
-	for _, commonPrefix := range result.CommonPrefixes {
-		obj := NewS3Object(
-			Dir,
-			aws.StringValue(commonPrefix.Prefix),
-			nil,
-			nil,
-		)
-		objects = append(objects, obj)
+// FetchObjects lists directories and objects just under prefix, returning error instead of exiting.
+func FetchObjects(bucket, prefix string) ([]*S3Object, error) {
+	objects, err := fetchObjects(getS3Client(), bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed list objects, %v", err)
 	}
-	for _, content := range result.Contents {
-		obj := NewS3Object(
-			Object,
-			aws.StringValue(content.Key),
-			content.LastModified,
-			content.Size,
-		)
-		objects = append(objects, obj)
+	return objects, nil
+}
+
+func fetchObjects(client *s3.S3, bucket, prefix string) ([]*S3Object, error) {
+	ctx := context.Background()
+	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
+	defer cancelFn()
+
+	var dirs, objects []*S3Object
+	err := client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(bucket),
+		Delimiter: aws.String("/"),
+		Prefix:    aws.String(prefix),
+	}, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, commonPrefix := range output.CommonPrefixes {
+			obj := NewS3Object(
+				Dir,
+				aws.StringValue(commonPrefix.Prefix),
+				nil,
+				nil,
+			)
+			dirs = append(dirs, obj)
+		}
+		for _, content := range output.Contents {
+			obj := NewS3Object(
+				Object,
+				aws.StringValue(content.Key),
+				content.LastModified,
+				content.Size,
+			)
+			obj.ETag = aws.StringValue(content.ETag)
+			obj.StorageClass = aws.StringValue(content.StorageClass)
+			objects = append(objects, obj)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
 	}
-	return objects
+	return append(dirs, objects...), nil
 }
 
 func Download(bucket, key string, file io.WriterAt) {
@@ -142,6 +197,60 @@ func Detail(bucket, key string) *s3.GetObjectOutput {
 	return result
 }
 
+func GetRange(bucket, key string, offset, length int64) (*s3.GetObjectOutput, []byte, error) {
+	client := getS3Client()
+
+	ctx := context.Background()
+	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
+	defer cancelFn()
+
+	result, err := client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed get object range, %v", err)
+	}
+	defer result.Body.Close()
+
+	b, err := ioutil.ReadAll(result.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed read object range, %v", err)
+	}
+	return result, b, nil
+}
+
+// OpenObject gets object to read its body as stream, without timeout.
+func OpenObject(bucket, key string) (*s3.GetObjectOutput, error) {
+	client := getS3Client()
+	result, err := client.GetObjectWithContext(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed get object, %v", err)
+	}
+	return result, nil
+}
+
+func Head(bucket, key string) (*s3.HeadObjectOutput, error) {
+	client := getS3Client()
+
+	ctx := context.Background()
+	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
+	defer cancelFn()
+
+	result, err := client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed head object, %v", err)
+	}
+	return result, nil
+}
+
 func Acl(bucket, key string) *s3.GetObjectAclOutput {
 	client := getS3Client()
 
@@ -163,50 +272,337 @@ func Acl(bucket, key string) *s3.GetObjectAclOutput {
 	return result
 }
 
-func Update(bucket, key string, body io.Reader) *s3.PutObjectOutput {
+// Update puts body to key with properties of the object it replaces.
+func Update(bucket, key string, body io.Reader, props *ObjectProperties) (*s3.PutObjectOutput, error) {
 	client := getS3Client()
 
 	ctx := context.Background()
 	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
 	defer cancelFn()
 
-	result, err := client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+	input := &s3.PutObjectInput{
 		Body:   aws.ReadSeekCloser(body),
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
+	}
+	props.applyTo(input)
+	result, err := client.PutObjectWithContext(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed put object, %v", err)
+	}
+	return result, nil
+}
+
+// ListAllObjects lists all objects under prefix without delimiter, following pages.
+func ListAllObjects(bucket, prefix string) ([]*S3Object, error) {
+	return listAllObjects(getS3Client(), bucket, prefix)
+}
+
+func listAllObjects(client *s3.S3, bucket, prefix string) ([]*S3Object, error) {
+	var objects []*S3Object
+	err := walkObjects(client, bucket, prefix, func(page []*S3Object) bool {
+		objects = append(objects, page...)
+		return true
+	})
+	return objects, err
+}
+
+// walkObjects calls fn with each page of objects under prefix until fn returns false.
+func walkObjects(client *s3.S3, bucket, prefix string, fn func([]*S3Object) bool) error {
+	ctx := context.Background()
+	err := client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
+		page := make([]*S3Object, 0, len(output.Contents))
+		for _, content := range output.Contents {
+			obj := NewS3Object(
+				Object,
+				aws.StringValue(content.Key),
+				content.LastModified,
+				content.Size,
+			)
+			obj.ETag = aws.StringValue(content.ETag)
+			obj.StorageClass = aws.StringValue(content.StorageClass)
+			page = append(page, obj)
+		}
+		return fn(page)
 	})
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
-			log.Fatalf("put object canceled due to timeout, %v", err)
-		} else {
-			log.Fatalf("failed put object, %v", err)
+		return fmt.Errorf("failed list objects, %v", err)
+	}
+	return nil
+}
+
+// Upload puts body to key by multipart upload when it is large.
+func Upload(bucket, key string, body io.Reader) error {
+	return upload(getS3Uploader(currentProfile()), bucket, key, body)
+}
+
+func upload(client *s3manager.Uploader, bucket, key string, body io.Reader) error {
+	_, err := client.UploadWithContext(context.Background(), &s3manager.UploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed upload, %v", err)
+	}
+	return nil
+}
+
+// PutFile uploads local file to key, with Content-Type guessed from its extension.
+func PutFile(bucket, key, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return put(bucket, key, f, mime.TypeByExtension(filepath.Ext(key)))
+}
+
+// MakeDir creates empty marker object of prefix, so that it is listed as directory.
+func MakeDir(bucket, prefix string) error {
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return put(bucket, prefix, bytes.NewReader(nil), "")
+}
+
+func put(bucket, key string, body io.ReadSeeker, contentType string) error {
+	client := getS3Client()
+
+	ctx := context.Background()
+	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
+	defer cancelFn()
+
+	input := &s3.PutObjectInput{
+		Body:   body,
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	if _, err := client.PutObjectWithContext(ctx, input); err != nil {
+		return fmt.Errorf("failed put object, %v", err)
+	}
+	return nil
+}
+
+// Exists reports whether object of key exists.
+func Exists(bucket, key string) (bool, error) {
+	client := getS3Client()
+
+	ctx := context.Background()
+	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
+	defer cancelFn()
+
+	_, err := client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err == nil {
+		return true, nil
+	}
+	if aerr, ok := err.(awserr.RequestFailure); ok && aerr.StatusCode() == http.StatusNotFound {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed head object, %v", err)
+}
+
+func Copy(srcBucket, srcKey, bucket, key string) error {
+	return copyObject(getS3Client(), srcBucket, srcKey, bucket, key)
+}
+
+func copyObject(client *s3.S3, srcBucket, srcKey, bucket, key string) error {
+	ctx := context.Background()
+	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
+	defer cancelFn()
+
+	_, err := client.CopyObjectWithContext(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(bucket),
+		Key:        aws.String(key),
+		CopySource: aws.String(url.PathEscape(srcBucket + "/" + srcKey)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed copy object, %v", err)
+	}
+	return nil
+}
+
+func Delete(bucket, key string) error {
+	return deleteObject(getS3Client(), bucket, key)
+}
+
+func deleteObject(client *s3.S3, bucket, key string) error {
+	ctx := context.Background()
+	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
+	defer cancelFn()
+
+	_, err := client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed delete object, %v", err)
+	}
+	return nil
+}
+
+// deleteBatchSize is maximum number of keys deleted by a DeleteObjects request.
+const deleteBatchSize = 1000
+
+// DeletePrefix deletes all objects under prefix, calling fn after each deletion.
+func DeletePrefix(bucket, prefix string, fn func(key string)) error {
+	return deletePrefix(getS3Client(), bucket, prefix, fn)
+}
+
+// DeleteAll deletes all objects under prefix of bucket on S3 of profile in the same way as DeletePrefix.
+func DeleteAll(profile, bucket, prefix string, fn func(key string)) error {
+	return deletePrefix(getS3ClientFor(profile), bucket, prefix, fn)
+}
+
+func deletePrefix(client *s3.S3, bucket, prefix string, fn func(key string)) error {
+	objects, err := listAllObjects(client, bucket, prefix)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, len(objects))
+	for i, obj := range objects {
+		keys[i] = obj.Name
+	}
+	return deleteKeys(client, bucket, keys, fn)
+}
+
+// DeleteKeys deletes objects of keys in bucket on S3 of profile.
+func DeleteKeys(profile, bucket string, keys []string) error {
+	return deleteKeys(getS3ClientFor(profile), bucket, keys, func(string) {})
+}
+
+// deleteKeys deletes keys by batches of DeleteObjects, calling fn after each deletion.
+func deleteKeys(client *s3.S3, bucket string, keys []string, fn func(key string)) error {
+	for start := 0; start < len(keys); start += deleteBatchSize {
+		end := start + deleteBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		if err := deleteBatch(client, bucket, keys[start:end], fn); err != nil {
+			return err
 		}
 	}
-	return result
+	return nil
 }
 
-func getS3Downloader() *s3manager.Downloader {
-	var sess *session.Session
-	if MockFlag {
-		sess = getMinioSession()
-	} else {
-		sess = getAWSSession()
+func deleteBatch(client *s3.S3, bucket string, keys []string, fn func(key string)) error {
+	ctx := context.Background()
+	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
+	defer cancelFn()
+
+	ids := make([]*s3.ObjectIdentifier, len(keys))
+	for i, key := range keys {
+		ids[i] = &s3.ObjectIdentifier{Key: aws.String(key)}
+	}
+	output, err := client.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+		Bucket: aws.String(bucket),
+		Delete: &s3.Delete{Objects: ids, Quiet: aws.Bool(true)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed delete objects, %v", err)
+	}
+	// quiet mode returns only failed keys
+	failed := map[string]bool{}
+	for _, e := range output.Errors {
+		failed[aws.StringValue(e.Key)] = true
+	}
+	for _, key := range keys {
+		if !failed[key] {
+			fn(key)
+		}
 	}
-	return s3manager.NewDownloader(sess)
+	if len(output.Errors) > 0 {
+		e := output.Errors[0]
+		return fmt.Errorf("failed delete %d objects, %s: %s", len(output.Errors), aws.StringValue(e.Key), aws.StringValue(e.Message))
+	}
+	return nil
 }
 
-func getS3Client() *s3.S3 {
+var (
+	sessions     = map[string]*session.Session{}
+	sessionMutex sync.Mutex
+)
+
+// SetProfile switches shared config profile used by package level functions.
+func SetProfile(name string) {
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+	profile = name
+}
+
+func currentProfile() string {
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+	return profile
+}
+
+// getSession returns session of profile, shared between clients.
+func getSession(profile string) *session.Session {
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+	if sess, ok := sessions[profile]; ok {
+		return sess
+	}
+
 	var sess *session.Session
 	if MockFlag {
 		sess = getMinioSession()
 	} else {
-		sess = getAWSSession()
+		sess = getAWSSession(profile)
 	}
-	return s3.New(sess)
+	sessions[profile] = sess
+	return sess
+}
+
+func getS3Downloader() *s3manager.Downloader {
+	return getS3DownloaderFor(currentProfile())
+}
+
+func getS3DownloaderFor(profile string) *s3manager.Downloader {
+	return s3manager.NewDownloader(getSession(profile))
+}
+
+func getS3Uploader(profile string) *s3manager.Uploader {
+	return s3manager.NewUploader(getSession(profile))
+}
+
+func getS3Client() *s3.S3 {
+	return getS3ClientFor(currentProfile())
+}
+
+func getS3ClientFor(profile string) *s3.S3 {
+	return s3.New(getSession(profile))
+}
+
+// httpClient is http client of sessions. It does not decompress body of Content-Encoding: gzip
+// as net/http does by default, dropping the header, so that objects are read as they are stored.
+var httpClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		DisableCompression:    true,
+	},
 }
 
 func getMinioSession() *session.Session {
 	cfg := &aws.Config{
+		HTTPClient:       httpClient,
 		Credentials:      credentials.NewStaticCredentials("access_key", "secret_key", ""),
 		Endpoint:         aws.String("localhost:9000"),
 		Region:           aws.String("ap-northeast-1"),
@@ -216,8 +612,10 @@ func getMinioSession() *session.Session {
 	return session.New(cfg)
 }
 
-func getAWSSession() *session.Session {
+func getAWSSession(profile string) *session.Session {
 	return session.Must(session.NewSessionWithOptions(session.Options{
+		Config:            aws.Config{HTTPClient: httpClient},
+		Profile:           profile,
 		SharedConfigState: session.SharedConfigEnable,
 	}))
 }
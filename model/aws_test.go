@@ -0,0 +1,36 @@
+package model
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestOpenObjectReturnsStoredBytes(t *testing.T) {
+	stored := gzipBytes(t, "hello")
+	f := &fakeS3{
+		objects: map[string][]byte{"hello.txt": stored},
+		puts:    map[string]*http.Request{},
+		bodies:  map[string][]byte{},
+	}
+	defer useFakeS3(t, f)()
+
+	result, err := OpenObject("bucket", "hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer result.Body.Close()
+	if got := aws.StringValue(result.ContentEncoding); got != "gzip" {
+		t.Errorf("content encoding = %q", got)
+	}
+	b, err := ioutil.ReadAll(result.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, stored) {
+		t.Errorf("body = %q, want stored gzip bytes", b)
+	}
+}
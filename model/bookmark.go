@@ -0,0 +1,100 @@
+package model
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/lighttiger2505/s3tf/internal"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Bookmark is a named location.
+type Bookmark struct {
+	Name     string `yaml:"name"`
+	Location `yaml:",inline"`
+}
+
+type BookmarkFile struct {
+	Items []*Bookmark `yaml:"items"`
+}
+
+// Find returns bookmark of name, nil when there is none.
+func (f *BookmarkFile) Find(name string) *Bookmark {
+	for _, b := range f.Items {
+		if b.Name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// Add appends bookmark, or replaces location of the one with the same name.
+func (f *BookmarkFile) Add(bookmark *Bookmark) {
+	if b := f.Find(bookmark.Name); b != nil {
+		b.Location = bookmark.Location
+		return
+	}
+	f.Items = append(f.Items, bookmark)
+}
+
+func (f *BookmarkFile) Remove(name string) {
+	for i, b := range f.Items {
+		if b.Name == name {
+			f.Items = append(f.Items[:i], f.Items[i+1:]...)
+			return
+		}
+	}
+}
+
+// Merge adds bookmarks of other file. Same names are replaced by other.
+func (f *BookmarkFile) Merge(other *BookmarkFile) {
+	for _, b := range other.Items {
+		f.Add(b)
+	}
+}
+
+func GetBookmarkFilePath() string {
+	return filepath.Join(internal.GetXDGConfigPath(), "bookmarks.yml")
+}
+
+// LoadBookmarkFile reads bookmarks saved next to download list, empty when there is none.
+func LoadBookmarkFile() (*BookmarkFile, error) {
+	f, err := ReadBookmarkFile(GetBookmarkFilePath())
+	if os.IsNotExist(err) {
+		return &BookmarkFile{}, nil
+	}
+	return f, err
+}
+
+func SaveBookmarkFile(f *BookmarkFile) error {
+	return WriteBookmarkFile(GetBookmarkFilePath(), f)
+}
+
+// ReadBookmarkFile reads bookmarks from path, used to import shared ones.
+func ReadBookmarkFile(path string) (*BookmarkFile, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &BookmarkFile{}
+	if err := yaml.Unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("Failed unmarshal yaml. Error: %s", err.Error())
+	}
+	for _, bookmark := range f.Items {
+		if bookmark.Name == "" {
+			return nil, fmt.Errorf("bookmark without name. %s", bookmark.Location.String())
+		}
+	}
+	return f, nil
+}
+
+// WriteBookmarkFile writes bookmarks to path, used to export them.
+func WriteBookmarkFile(path string, f *BookmarkFile) error {
+	out, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("Failed marshal config. Error: %v", err.Error())
+	}
+	return ioutil.WriteFile(path, out, 0644)
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBookmarkFileMerge(t *testing.T) {
+	f := &BookmarkFile{Items: []*Bookmark{
+		{Name: "logs", Location: Location{Bucket: "bucket", Key: "logs/"}},
+		{Name: "home", Location: Location{Bucket: "/", Key: "home/", Local: true}},
+	}}
+	f.Merge(&BookmarkFile{Items: []*Bookmark{
+		{Name: "logs", Location: Location{Profile: "prod", Bucket: "bucket", Key: "logs/2020/"}},
+		{Name: "data", Location: Location{Bucket: "data"}},
+	}})
+
+	want := []*Bookmark{
+		{Name: "logs", Location: Location{Profile: "prod", Bucket: "bucket", Key: "logs/2020/"}},
+		{Name: "home", Location: Location{Bucket: "/", Key: "home/", Local: true}},
+		{Name: "data", Location: Location{Bucket: "data"}},
+	}
+	if !reflect.DeepEqual(f.Items, want) {
+		t.Errorf("merged %v, want %v", f.Items, want)
+	}
+
+	f.Remove("home")
+	if f.Find("home") != nil || len(f.Items) != 2 {
+		t.Errorf("not removed, %v", f.Items)
+	}
+}
+
+func TestBookmarkFileReadWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s3tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "bookmarks.yml")
+	f := &BookmarkFile{Items: []*Bookmark{
+		{Name: "logs", Location: Location{Profile: "prod", Bucket: "bucket", Key: "logs/"}},
+	}}
+	if err := WriteBookmarkFile(path, f); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadBookmarkFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, f) {
+		t.Errorf("read %v, want %v", got, f)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("items:\n- bucket: bucket\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadBookmarkFile(path); err == nil {
+		t.Error("want error for bookmark without name")
+	}
+}
@@ -0,0 +1,596 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// BucketConfig is kind of bucket level configuration edited as document.
+type BucketConfig string
+
+const (
+	ConfigPolicy    BucketConfig = "policy"
+	ConfigLifecycle BucketConfig = "lifecycle"
+	ConfigCORS      BucketConfig = "cors"
+)
+
+// Filename returns name of the file to edit configuration, extension tells its format to editor.
+func (c BucketConfig) Filename() string {
+	if c == ConfigPolicy {
+		return "policy.json"
+	}
+	return string(c) + ".yml"
+}
+
+const (
+	maxLifecycleRules = 1000
+	maxCORSRules      = 100
+	dateLayout        = "2006-01-02"
+)
+
+var corsMethods = []string{"GET", "PUT", "POST", "DELETE", "HEAD"}
+
+// storageClasses are literals as the pinned aws-sdk-go lacks constants of the newer classes.
+var storageClasses = []string{
+	"STANDARD_IA",
+	"ONEZONE_IA",
+	"INTELLIGENT_TIERING",
+	"GLACIER",
+	"DEEP_ARCHIVE",
+}
+
+type CORSRuleDoc struct {
+	AllowedHeaders []string `yaml:"allowed_headers,omitempty"`
+	AllowedMethods []string `yaml:"allowed_methods"`
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	ExposeHeaders  []string `yaml:"expose_headers,omitempty"`
+	MaxAgeSeconds  int64    `yaml:"max_age_seconds,omitempty"`
+}
+
+type CORSDoc struct {
+	Rules []*CORSRuleDoc `yaml:"rules"`
+}
+
+type TransitionDoc struct {
+	Days         int64  `yaml:"days,omitempty"`
+	Date         string `yaml:"date,omitempty"`
+	StorageClass string `yaml:"storage_class"`
+}
+
+type LifecycleRuleDoc struct {
+	ID                                 string            `yaml:"id"`
+	Status                             string            `yaml:"status"`
+	Prefix                             string            `yaml:"prefix,omitempty"`
+	Tags                               map[string]string `yaml:"tags,omitempty"`
+	ExpirationDays                     int64             `yaml:"expiration_days,omitempty"`
+	ExpirationDate                     string            `yaml:"expiration_date,omitempty"`
+	ExpiredObjectDeleteMarker          bool              `yaml:"expired_object_delete_marker,omitempty"`
+	Transitions                        []*TransitionDoc  `yaml:"transitions,omitempty"`
+	NoncurrentExpirationDays           int64             `yaml:"noncurrent_expiration_days,omitempty"`
+	NoncurrentTransitions              []*TransitionDoc  `yaml:"noncurrent_transitions,omitempty"`
+	AbortIncompleteMultipartUploadDays int64             `yaml:"abort_incomplete_multipart_upload_days,omitempty"`
+}
+
+type LifecycleDoc struct {
+	Rules []*LifecycleRuleDoc `yaml:"rules"`
+}
+
+// PolicyStatement is a statement of bucket policy, with fields which are a string or a list kept raw.
+type PolicyStatement struct {
+	Sid          string          `json:"Sid,omitempty"`
+	Effect       string          `json:"Effect"`
+	Principal    json.RawMessage `json:"Principal,omitempty"`
+	NotPrincipal json.RawMessage `json:"NotPrincipal,omitempty"`
+	Action       json.RawMessage `json:"Action,omitempty"`
+	NotAction    json.RawMessage `json:"NotAction,omitempty"`
+	Resource     json.RawMessage `json:"Resource,omitempty"`
+	NotResource  json.RawMessage `json:"NotResource,omitempty"`
+	Condition    json.RawMessage `json:"Condition,omitempty"`
+}
+
+type PolicyDoc struct {
+	Version   string
+	Statement []*PolicyStatement
+}
+
+// GetBucketConfig returns configuration of bucket as document to edit.
+// Bucket without the configuration gets an empty document.
+func GetBucketConfig(bucket string, kind BucketConfig) ([]byte, error) {
+	client := getS3Client()
+
+	ctx := context.Background()
+	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
+	defer cancelFn()
+
+	switch kind {
+	case ConfigPolicy:
+		result, err := client.GetBucketPolicyWithContext(ctx, &s3.GetBucketPolicyInput{Bucket: aws.String(bucket)})
+		if isErrorCode(err, "NoSuchBucketPolicy") {
+			return []byte("{\n  \"Version\": \"2012-10-17\",\n  \"Statement\": []\n}\n"), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed get bucket policy, %v", err)
+		}
+		return NormalizeBucketConfig(kind, []byte(aws.StringValue(result.Policy)))
+	case ConfigLifecycle:
+		result, err := client.GetBucketLifecycleConfigurationWithContext(ctx, &s3.GetBucketLifecycleConfigurationInput{Bucket: aws.String(bucket)})
+		if isErrorCode(err, "NoSuchLifecycleConfiguration") {
+			return yaml.Marshal(&LifecycleDoc{Rules: []*LifecycleRuleDoc{}})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed get bucket lifecycle configuration, %v", err)
+		}
+		return yaml.Marshal(newLifecycleDoc(result.Rules))
+	case ConfigCORS:
+		result, err := client.GetBucketCorsWithContext(ctx, &s3.GetBucketCorsInput{Bucket: aws.String(bucket)})
+		if isErrorCode(err, "NoSuchCORSConfiguration") {
+			return yaml.Marshal(&CORSDoc{Rules: []*CORSRuleDoc{}})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed get bucket cors, %v", err)
+		}
+		return yaml.Marshal(newCORSDoc(result.CORSRules))
+	}
+	return nil, fmt.Errorf("unknown bucket config %q", kind)
+}
+
+func isErrorCode(err error, code string) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == code
+}
+
+// NormalizeBucketConfig validates document and formats it in the form GetBucketConfig returns,
+// so that documents can be compared line by line.
+func NormalizeBucketConfig(kind BucketConfig, b []byte) ([]byte, error) {
+	doc, err := ParseBucketConfig(kind, b)
+	if err != nil {
+		return nil, err
+	}
+	if kind == ConfigPolicy {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, bytes.TrimSpace(b), "", "  "); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	}
+	return yaml.Marshal(doc)
+}
+
+// ParseBucketConfig reads configuration document and validates its structure.
+func ParseBucketConfig(kind BucketConfig, b []byte) (interface{}, error) {
+	switch kind {
+	case ConfigPolicy:
+		doc := &PolicyDoc{}
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(doc); err != nil {
+			return nil, fmt.Errorf("invalid policy, %v", err)
+		}
+		return doc, doc.Validate()
+	case ConfigLifecycle:
+		doc := &LifecycleDoc{}
+		if err := yaml.UnmarshalStrict(b, doc); err != nil {
+			return nil, fmt.Errorf("invalid lifecycle configuration, %v", err)
+		}
+		return doc, doc.Validate()
+	case ConfigCORS:
+		doc := &CORSDoc{}
+		if err := yaml.UnmarshalStrict(b, doc); err != nil {
+			return nil, fmt.Errorf("invalid cors configuration, %v", err)
+		}
+		return doc, doc.Validate()
+	}
+	return nil, fmt.Errorf("unknown bucket config %q", kind)
+}
+
+// UnmarshalJSON accepts a statement object as well as a list of statements.
+func (doc *PolicyDoc) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Version   string
+		Id        string
+		Statement json.RawMessage
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	doc.Version = raw.Version
+	if len(raw.Statement) == 0 {
+		return nil
+	}
+	if raw.Statement[0] == '{' {
+		raw.Statement = append(append([]byte{'['}, raw.Statement...), ']')
+	}
+	dec = json.NewDecoder(bytes.NewReader(raw.Statement))
+	dec.DisallowUnknownFields()
+	return dec.Decode(&doc.Statement)
+}
+
+func (doc *PolicyDoc) Validate() error {
+	if doc.Version != "" && doc.Version != "2012-10-17" && doc.Version != "2008-10-17" {
+		return fmt.Errorf("invalid policy version %q", doc.Version)
+	}
+	for i, st := range doc.Statement {
+		name := st.name(i)
+		if st.Effect != "Allow" && st.Effect != "Deny" {
+			return fmt.Errorf("statement %s: Effect must be Allow or Deny", name)
+		}
+		if err := exactlyOne(name, "Principal", st.Principal, "NotPrincipal", st.NotPrincipal); err != nil {
+			return err
+		}
+		if err := exactlyOne(name, "Action", st.Action, "NotAction", st.NotAction); err != nil {
+			return err
+		}
+		if err := exactlyOne(name, "Resource", st.Resource, "NotResource", st.NotResource); err != nil {
+			return err
+		}
+		for field, raw := range map[string]json.RawMessage{"Action": st.Action, "NotAction": st.NotAction, "Resource": st.Resource, "NotResource": st.NotResource} {
+			if raw == nil {
+				continue
+			}
+			if _, err := stringList(raw); err != nil {
+				return fmt.Errorf("statement %s: %s must be a string or a list of strings", name, field)
+			}
+		}
+	}
+	return nil
+}
+
+func (st *PolicyStatement) name(i int) string {
+	if st.Sid != "" {
+		return st.Sid
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
+
+func exactlyOne(name, field string, v json.RawMessage, notField string, notV json.RawMessage) error {
+	if (v == nil) == (notV == nil) {
+		return fmt.Errorf("statement %s: one of %s or %s is required", name, field, notField)
+	}
+	return nil
+}
+
+func stringList(raw json.RawMessage) ([]string, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return []string{s}, nil
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// PublicStatements returns names of statements allowing access to anyone by Principal "*".
+func (doc *PolicyDoc) PublicStatements() []string {
+	var names []string
+	for i, st := range doc.Statement {
+		if st.Effect == "Allow" && isPublicPrincipal(st.Principal) {
+			names = append(names, st.name(i))
+		}
+	}
+	return names
+}
+
+func isPublicPrincipal(raw json.RawMessage) bool {
+	if raw == nil {
+		return false
+	}
+	if list, err := stringList(raw); err == nil {
+		return contains(list, "*")
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return false
+	}
+	if aws, ok := m["AWS"]; ok {
+		list, _ := stringList(aws)
+		return contains(list, "*")
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (doc *LifecycleDoc) Validate() error {
+	if len(doc.Rules) > maxLifecycleRules {
+		return fmt.Errorf("%d lifecycle rules, must be at most %d", len(doc.Rules), maxLifecycleRules)
+	}
+	ids := map[string]bool{}
+	for i, r := range doc.Rules {
+		name := r.ID
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		} else if ids[r.ID] {
+			return fmt.Errorf("rule %s: id is duplicated", name)
+		}
+		ids[r.ID] = true
+		if len(r.ID) > 255 {
+			return fmt.Errorf("rule %s: id must be at most 255 characters", name)
+		}
+		if r.Status != s3.ExpirationStatusEnabled && r.Status != s3.ExpirationStatusDisabled {
+			return fmt.Errorf("rule %s: status must be Enabled or Disabled", name)
+		}
+		if r.ExpirationDays == 0 && r.ExpirationDate == "" && !r.ExpiredObjectDeleteMarker &&
+			len(r.Transitions) == 0 && r.NoncurrentExpirationDays == 0 &&
+			len(r.NoncurrentTransitions) == 0 && r.AbortIncompleteMultipartUploadDays == 0 {
+			return fmt.Errorf("rule %s: at least one action is required", name)
+		}
+		if r.ExpirationDays < 0 || r.NoncurrentExpirationDays < 0 || r.AbortIncompleteMultipartUploadDays < 0 {
+			return fmt.Errorf("rule %s: days must not be negative", name)
+		}
+		if r.ExpirationDays > 0 && r.ExpirationDate != "" {
+			return fmt.Errorf("rule %s: expiration_days and expiration_date are exclusive", name)
+		}
+		if r.ExpirationDate != "" {
+			if _, err := time.Parse(dateLayout, r.ExpirationDate); err != nil {
+				return fmt.Errorf("rule %s: expiration_date must be YYYY-MM-DD", name)
+			}
+		}
+		for _, t := range append(append([]*TransitionDoc{}, r.Transitions...), r.NoncurrentTransitions...) {
+			if !contains(storageClasses, t.StorageClass) {
+				return fmt.Errorf("rule %s: storage_class must be one of %s", name, strings.Join(storageClasses, ", "))
+			}
+			if t.Days < 0 {
+				return fmt.Errorf("rule %s: days must not be negative", name)
+			}
+			if t.Date != "" {
+				if _, err := time.Parse(dateLayout, t.Date); err != nil {
+					return fmt.Errorf("rule %s: transition date must be YYYY-MM-DD", name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (doc *CORSDoc) Validate() error {
+	if len(doc.Rules) > maxCORSRules {
+		return fmt.Errorf("%d cors rules, must be at most %d", len(doc.Rules), maxCORSRules)
+	}
+	for i, r := range doc.Rules {
+		if len(r.AllowedMethods) == 0 {
+			return fmt.Errorf("rule #%d: allowed_methods is required", i+1)
+		}
+		for _, m := range r.AllowedMethods {
+			if !contains(corsMethods, m) {
+				return fmt.Errorf("rule #%d: method %q must be one of %s", i+1, m, strings.Join(corsMethods, ", "))
+			}
+		}
+		if len(r.AllowedOrigins) == 0 {
+			return fmt.Errorf("rule #%d: allowed_origins is required", i+1)
+		}
+		for _, o := range r.AllowedOrigins {
+			if strings.Count(o, "*") > 1 {
+				return fmt.Errorf("rule #%d: origin %q can contain at most one wildcard", i+1, o)
+			}
+		}
+		if r.MaxAgeSeconds < 0 {
+			return fmt.Errorf("rule #%d: max_age_seconds must not be negative", i+1)
+		}
+	}
+	return nil
+}
+
+// PutBucketConfig validates document and sets it as configuration of bucket.
+// Document without statements or rules deletes the configuration.
+func PutBucketConfig(bucket string, kind BucketConfig, b []byte) error {
+	doc, err := ParseBucketConfig(kind, b)
+	if err != nil {
+		return err
+	}
+	client := getS3Client()
+
+	ctx := context.Background()
+	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
+	defer cancelFn()
+
+	switch doc := doc.(type) {
+	case *PolicyDoc:
+		if len(doc.Statement) == 0 {
+			_, err = client.DeleteBucketPolicyWithContext(ctx, &s3.DeleteBucketPolicyInput{Bucket: aws.String(bucket)})
+			break
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, b); err != nil {
+			return err
+		}
+		_, err = client.PutBucketPolicyWithContext(ctx, &s3.PutBucketPolicyInput{
+			Bucket: aws.String(bucket),
+			Policy: aws.String(buf.String()),
+		})
+	case *LifecycleDoc:
+		if len(doc.Rules) == 0 {
+			_, err = client.DeleteBucketLifecycleWithContext(ctx, &s3.DeleteBucketLifecycleInput{Bucket: aws.String(bucket)})
+			break
+		}
+		_, err = client.PutBucketLifecycleConfigurationWithContext(ctx, &s3.PutBucketLifecycleConfigurationInput{
+			Bucket:                 aws.String(bucket),
+			LifecycleConfiguration: &s3.BucketLifecycleConfiguration{Rules: doc.rules()},
+		})
+	case *CORSDoc:
+		if len(doc.Rules) == 0 {
+			_, err = client.DeleteBucketCorsWithContext(ctx, &s3.DeleteBucketCorsInput{Bucket: aws.String(bucket)})
+			break
+		}
+		_, err = client.PutBucketCorsWithContext(ctx, &s3.PutBucketCorsInput{
+			Bucket:            aws.String(bucket),
+			CORSConfiguration: &s3.CORSConfiguration{CORSRules: doc.rules()},
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed put bucket %s, %v", kind, err)
+	}
+	return nil
+}
+
+func newCORSDoc(rules []*s3.CORSRule) *CORSDoc {
+	doc := &CORSDoc{Rules: []*CORSRuleDoc{}}
+	for _, r := range rules {
+		doc.Rules = append(doc.Rules, &CORSRuleDoc{
+			AllowedHeaders: aws.StringValueSlice(r.AllowedHeaders),
+			AllowedMethods: aws.StringValueSlice(r.AllowedMethods),
+			AllowedOrigins: aws.StringValueSlice(r.AllowedOrigins),
+			ExposeHeaders:  aws.StringValueSlice(r.ExposeHeaders),
+			MaxAgeSeconds:  aws.Int64Value(r.MaxAgeSeconds),
+		})
+	}
+	return doc
+}
+
+func (doc *CORSDoc) rules() []*s3.CORSRule {
+	var rules []*s3.CORSRule
+	for _, r := range doc.Rules {
+		rule := &s3.CORSRule{
+			AllowedHeaders: aws.StringSlice(r.AllowedHeaders),
+			AllowedMethods: aws.StringSlice(r.AllowedMethods),
+			AllowedOrigins: aws.StringSlice(r.AllowedOrigins),
+			ExposeHeaders:  aws.StringSlice(r.ExposeHeaders),
+		}
+		if r.MaxAgeSeconds > 0 {
+			rule.MaxAgeSeconds = aws.Int64(r.MaxAgeSeconds)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func newLifecycleDoc(rules []*s3.LifecycleRule) *LifecycleDoc {
+	doc := &LifecycleDoc{Rules: []*LifecycleRuleDoc{}}
+	for _, r := range rules {
+		rule := &LifecycleRuleDoc{
+			ID:     aws.StringValue(r.ID),
+			Status: aws.StringValue(r.Status),
+			Prefix: aws.StringValue(r.Prefix),
+		}
+		if f := r.Filter; f != nil {
+			if f.Prefix != nil {
+				rule.Prefix = aws.StringValue(f.Prefix)
+			}
+			if f.Tag != nil {
+				rule.Tags = map[string]string{aws.StringValue(f.Tag.Key): aws.StringValue(f.Tag.Value)}
+			}
+			if f.And != nil {
+				rule.Prefix = aws.StringValue(f.And.Prefix)
+				rule.Tags = map[string]string{}
+				for _, tag := range f.And.Tags {
+					rule.Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+				}
+			}
+		}
+		if e := r.Expiration; e != nil {
+			rule.ExpirationDays = aws.Int64Value(e.Days)
+			rule.ExpiredObjectDeleteMarker = aws.BoolValue(e.ExpiredObjectDeleteMarker)
+			if e.Date != nil {
+				rule.ExpirationDate = e.Date.UTC().Format(dateLayout)
+			}
+		}
+		for _, t := range r.Transitions {
+			td := &TransitionDoc{Days: aws.Int64Value(t.Days), StorageClass: aws.StringValue(t.StorageClass)}
+			if t.Date != nil {
+				td.Date = t.Date.UTC().Format(dateLayout)
+			}
+			rule.Transitions = append(rule.Transitions, td)
+		}
+		if r.NoncurrentVersionExpiration != nil {
+			rule.NoncurrentExpirationDays = aws.Int64Value(r.NoncurrentVersionExpiration.NoncurrentDays)
+		}
+		for _, t := range r.NoncurrentVersionTransitions {
+			rule.NoncurrentTransitions = append(rule.NoncurrentTransitions, &TransitionDoc{
+				Days:         aws.Int64Value(t.NoncurrentDays),
+				StorageClass: aws.StringValue(t.StorageClass),
+			})
+		}
+		if r.AbortIncompleteMultipartUpload != nil {
+			rule.AbortIncompleteMultipartUploadDays = aws.Int64Value(r.AbortIncompleteMultipartUpload.DaysAfterInitiation)
+		}
+		doc.Rules = append(doc.Rules, rule)
+	}
+	return doc
+}
+
+func (doc *LifecycleDoc) rules() []*s3.LifecycleRule {
+	var rules []*s3.LifecycleRule
+	for _, r := range doc.Rules {
+		rule := &s3.LifecycleRule{
+			ID:     aws.String(r.ID),
+			Status: aws.String(r.Status),
+			Filter: &s3.LifecycleRuleFilter{},
+		}
+		if r.ID == "" {
+			rule.ID = nil
+		}
+		switch {
+		case len(r.Tags) == 0:
+			rule.Filter.Prefix = aws.String(r.Prefix)
+		case len(r.Tags) == 1 && r.Prefix == "":
+			rule.Filter.Tag = tagSet(r.Tags)[0]
+		default:
+			rule.Filter.And = &s3.LifecycleRuleAndOperator{Prefix: aws.String(r.Prefix), Tags: tagSet(r.Tags)}
+		}
+		if r.ExpirationDays > 0 || r.ExpirationDate != "" || r.ExpiredObjectDeleteMarker {
+			rule.Expiration = &s3.LifecycleExpiration{}
+			if r.ExpirationDays > 0 {
+				rule.Expiration.Days = aws.Int64(r.ExpirationDays)
+			}
+			if r.ExpirationDate != "" {
+				rule.Expiration.Date = parseDate(r.ExpirationDate)
+			}
+			if r.ExpiredObjectDeleteMarker {
+				rule.Expiration.ExpiredObjectDeleteMarker = aws.Bool(true)
+			}
+		}
+		for _, t := range r.Transitions {
+			tr := &s3.Transition{StorageClass: aws.String(t.StorageClass)}
+			if t.Date != "" {
+				tr.Date = parseDate(t.Date)
+			} else {
+				tr.Days = aws.Int64(t.Days)
+			}
+			rule.Transitions = append(rule.Transitions, tr)
+		}
+		if r.NoncurrentExpirationDays > 0 {
+			rule.NoncurrentVersionExpiration = &s3.NoncurrentVersionExpiration{NoncurrentDays: aws.Int64(r.NoncurrentExpirationDays)}
+		}
+		for _, t := range r.NoncurrentTransitions {
+			rule.NoncurrentVersionTransitions = append(rule.NoncurrentVersionTransitions, &s3.NoncurrentVersionTransition{
+				NoncurrentDays: aws.Int64(t.Days),
+				StorageClass:   aws.String(t.StorageClass),
+			})
+		}
+		if r.AbortIncompleteMultipartUploadDays > 0 {
+			rule.AbortIncompleteMultipartUpload = &s3.AbortIncompleteMultipartUpload{
+				DaysAfterInitiation: aws.Int64(r.AbortIncompleteMultipartUploadDays),
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func parseDate(s string) *time.Time {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
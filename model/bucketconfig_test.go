@@ -0,0 +1,64 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBucketConfigPolicy(t *testing.T) {
+	tests := []struct {
+		doc     string
+		public  []string
+		wantErr bool
+	}{
+		{
+			doc:    `{"Version":"2012-10-17","Statement":[{"Sid":"Read","Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"}]}`,
+			public: []string{"Read"},
+		},
+		{
+			doc:    `{"Statement":{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":"arn:aws:s3:::b/*"}}`,
+			public: []string{"#1"},
+		},
+		{
+			doc: `{"Statement":[{"Effect":"Deny","Principal":"*","Action":"s3:*","Resource":"arn:aws:s3:::b/*"}]}`,
+		},
+		{doc: `{"Statement":[{"Effect":"Permit","Principal":"*","Action":"s3:*","Resource":"*"}]}`, wantErr: true},
+		{doc: `{"Statement":[{"Effect":"Allow","Principal":"*","Resource":"*"}]}`, wantErr: true},
+		{doc: `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:*","Resource":"*","Extra":1}]}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		doc, err := ParseBucketConfig(ConfigPolicy, []byte(tt.doc))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseBucketConfig(%s) error = %v, wantErr %v", tt.doc, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := doc.(*PolicyDoc).PublicStatements(); !reflect.DeepEqual(got, tt.public) {
+			t.Errorf("PublicStatements() = %v, want %v", got, tt.public)
+		}
+	}
+}
+
+func TestLifecycleDocRoundTrip(t *testing.T) {
+	doc := &LifecycleDoc{Rules: []*LifecycleRuleDoc{{
+		ID:             "logs",
+		Status:         "Enabled",
+		Prefix:         "logs/",
+		Tags:           map[string]string{"env": "dev"},
+		ExpirationDays: 365,
+		Transitions:    []*TransitionDoc{{Days: 30, StorageClass: "STANDARD_IA"}},
+	}}}
+	if err := doc.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := newLifecycleDoc(doc.rules()); !reflect.DeepEqual(got, doc) {
+		t.Errorf("round trip = %+v, want %+v", got.Rules[0], doc.Rules[0])
+	}
+
+	doc.Rules[0].Transitions[0].StorageClass = "COLD"
+	if err := doc.Validate(); err == nil {
+		t.Error("invalid storage class is accepted")
+	}
+}
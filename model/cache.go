@@ -0,0 +1,104 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// IsStale reports whether listing of n is older than ttl or invalidated.
+// Zero ttl keeps listings until they are invalidated.
+func (n *Node) IsStale(ttl time.Duration) bool {
+	if n.ListedAt.IsZero() {
+		return true
+	}
+	return ttl > 0 && time.Since(n.ListedAt) > ttl
+}
+
+// Invalidate marks listing of n stale to be listed again.
+func (n *Node) Invalidate() {
+	n.ListedAt = time.Time{}
+}
+
+// invalidateTree marks n and its visited descendants stale.
+func (n *Node) invalidateTree() {
+	n.Invalidate()
+	for _, child := range n.children {
+		child.invalidateTree()
+	}
+}
+
+// Refresh replaces listing of n, keeping the cursor on the same entry.
+func (n *Node) Refresh(objects []*S3Object) {
+	var name string
+	if n.Position < len(n.Objects) {
+		name = n.Objects[n.Position].Name
+	}
+	n.Objects = objects
+	n.ListedAt = time.Now()
+	n.CachedAt = time.Time{}
+	for i, obj := range objects {
+		if obj.Name == name {
+			n.Position = i
+			return
+		}
+	}
+	if n.Position >= len(objects) {
+		n.Position = len(objects) - 1
+	}
+	if n.Position < 0 {
+		n.Position = 0
+	}
+}
+
+// Lookup returns visited node of prefix in bucket under root node n, nil when it has not been visited.
+func (n *Node) Lookup(bucket, prefix string) *Node {
+	node := n.GetChild(bucket)
+	dir := ""
+	for _, name := range strings.SplitAfter(prefix, "/") {
+		if node == nil || name == "" {
+			break
+		}
+		dir += name
+		node = node.GetChild(dir)
+	}
+	return node
+}
+
+// InvalidateKey marks listings changed by writing key in bucket under root node n stale,
+// the directory containing key and, for a directory key, the visited nodes under it.
+func (n *Node) InvalidateKey(bucket, key string) {
+	if strings.HasSuffix(key, "/") {
+		if node := n.Lookup(bucket, key); node != nil {
+			node.invalidateTree()
+		}
+	}
+	trimmed := strings.TrimSuffix(key, "/")
+	if node := n.Lookup(bucket, trimmed[:strings.LastIndex(trimmed, "/")+1]); node != nil {
+		node.Invalidate()
+	}
+}
+
+// refetcher is lister which can list bypassing its cache.
+type refetcher interface {
+	Refetch(bucket, prefix string) ([]*S3Object, error)
+}
+
+// Relist lists objects of n again, returning error instead of exiting when lister supports it.
+func Relist(lister Lister, bucket string, n *Node) ([]*S3Object, error) {
+	if r, ok := lister.(refetcher); ok {
+		if n.IsRoot() {
+			return r.Refetch("", "")
+		}
+		return r.Refetch(bucket, n.Prefix())
+	}
+	f, ok := lister.(fetcher)
+	switch {
+	case ok && n.IsRoot():
+		return f.FetchBuckets()
+	case ok:
+		return f.FetchObjects(bucket, n.Prefix())
+	case n.IsRoot():
+		return lister.ListBuckets(), nil
+	}
+	return lister.ListObjects(bucket, n.Prefix()), nil
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNodeInvalidateKey(t *testing.T) {
+	root := NewNode("", nil, nil)
+	bucket := NewNode("bucket", root, nil)
+	root.AddChild("bucket", bucket)
+	logs := NewNode("logs/", bucket, nil)
+	bucket.AddChild("logs/", logs)
+	day := NewNode("logs/2020/", logs, nil)
+	logs.AddChild("logs/2020/", day)
+
+	if got := root.Lookup("bucket", "logs/2020/"); got != day {
+		t.Fatalf("lookup %v, want %v", got, day)
+	}
+	if got := root.Lookup("bucket", "data/"); got != nil {
+		t.Fatalf("lookup %v, want nil", got)
+	}
+
+	root.InvalidateKey("bucket", "logs/a.txt")
+	if !logs.IsStale(0) || bucket.IsStale(0) || day.IsStale(0) {
+		t.Errorf("want only logs/ stale, bucket:%v logs:%v day:%v", bucket.IsStale(0), logs.IsStale(0), day.IsStale(0))
+	}
+
+	logs.Refresh(nil)
+	root.InvalidateKey("bucket", "logs/")
+	if !bucket.IsStale(0) || !logs.IsStale(0) || !day.IsStale(0) {
+		t.Errorf("want all stale, bucket:%v logs:%v day:%v", bucket.IsStale(0), logs.IsStale(0), day.IsStale(0))
+	}
+}
+
+func TestNodeRefresh(t *testing.T) {
+	n := NewNode("dir/", nil, []*S3Object{
+		NewS3Object(PreDir, "..", nil, nil),
+		NewS3Object(Object, "dir/a", nil, nil),
+		NewS3Object(Object, "dir/b", nil, nil),
+	})
+	n.Position = 2
+	n.ListedAt = time.Now().Add(-time.Hour)
+	if !n.IsStale(time.Minute) {
+		t.Error("want stale after ttl")
+	}
+
+	n.Refresh([]*S3Object{
+		NewS3Object(PreDir, "..", nil, nil),
+		NewS3Object(Object, "dir/0", nil, nil),
+		NewS3Object(Object, "dir/a", nil, nil),
+		NewS3Object(Object, "dir/b", nil, nil),
+	})
+	if n.Position != 3 {
+		t.Errorf("position %d, want 3", n.Position)
+	}
+	if n.IsStale(time.Minute) {
+		t.Error("want fresh after refresh")
+	}
+
+	n.Refresh([]*S3Object{NewS3Object(PreDir, "..", nil, nil)})
+	if n.Position != 0 {
+		t.Errorf("position %d, want 0", n.Position)
+	}
+}
@@ -0,0 +1,208 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/lighttiger2505/s3tf/internal"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// SmallObjectSize is size under which objects are counted as small in DirUsage,
+// to be billed by minimum object size of price table.
+const SmallObjectSize = 128 * 1024
+
+const bytesPerGB = 1024 * 1024 * 1024
+
+// ClassPrice is price of a storage class.
+type ClassPrice struct {
+	PerGBMonth float64 `yaml:"per_gb_month"`
+	// MinObjectSize is billed size of small objects.
+	MinObjectSize int64 `yaml:"min_object_size,omitempty"`
+	// OverheadBytes is billed per object in addition to the size, e.g. index of Glacier.
+	OverheadBytes int64 `yaml:"overhead_bytes,omitempty"`
+	// MinDays is minimum storage duration charged for objects deleted or moved earlier.
+	MinDays int `yaml:"min_days,omitempty"`
+	// TransitionPer1000 is price of lifecycle transition requests into the class.
+	TransitionPer1000 float64 `yaml:"transition_per_1000,omitempty"`
+}
+
+// PriceTable is monthly storage price by region and storage class.
+type PriceTable struct {
+	Currency      string                            `yaml:"currency"`
+	DefaultRegion string                            `yaml:"default_region"`
+	Regions       map[string]map[string]*ClassPrice `yaml:"regions"`
+}
+
+// DefaultPriceTable returns list prices of us-east-1 to be edited for other regions.
+func DefaultPriceTable() *PriceTable {
+	return &PriceTable{
+		Currency:      "USD",
+		DefaultRegion: "us-east-1",
+		Regions: map[string]map[string]*ClassPrice{
+			"us-east-1": {
+				"STANDARD":            {PerGBMonth: 0.023},
+				"INTELLIGENT_TIERING": {PerGBMonth: 0.023, TransitionPer1000: 0.01},
+				"STANDARD_IA":         {PerGBMonth: 0.0125, MinObjectSize: SmallObjectSize, MinDays: 30, TransitionPer1000: 0.01},
+				"ONEZONE_IA":          {PerGBMonth: 0.01, MinObjectSize: SmallObjectSize, MinDays: 30, TransitionPer1000: 0.01},
+				"GLACIER_IR":          {PerGBMonth: 0.004, MinObjectSize: SmallObjectSize, MinDays: 90, TransitionPer1000: 0.02},
+				"GLACIER":             {PerGBMonth: 0.0036, OverheadBytes: 40 * 1024, MinDays: 90, TransitionPer1000: 0.03},
+				"DEEP_ARCHIVE":        {PerGBMonth: 0.00099, OverheadBytes: 40 * 1024, MinDays: 180, TransitionPer1000: 0.05},
+				"REDUCED_REDUNDANCY":  {PerGBMonth: 0.024},
+			},
+		},
+	}
+}
+
+func getPriceTablePath() string {
+	return filepath.Join(internal.GetXDGConfigPath(), "prices.yml")
+}
+
+// LoadPriceTable reads price table from config directory, writing default one when there is none.
+func LoadPriceTable() (*PriceTable, error) {
+	path := getPriceTablePath()
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		t := DefaultPriceTable()
+		out, err := yaml.Marshal(t)
+		if err != nil {
+			return nil, fmt.Errorf("Failed marshal config. Error: %v", err.Error())
+		}
+		if err := ioutil.WriteFile(path, out, 0644); err != nil {
+			return nil, fmt.Errorf("Failed write config file. Error: %s", err.Error())
+		}
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t := &PriceTable{}
+	if err := yaml.Unmarshal(b, t); err != nil {
+		return nil, fmt.Errorf("Failed unmarshal yaml. Error: %s", err.Error())
+	}
+	return t, nil
+}
+
+// prices returns prices of region, or of the default region when the table lacks it.
+func (t *PriceTable) prices(region string) (string, map[string]*ClassPrice, error) {
+	if prices, ok := t.Regions[region]; ok {
+		return region, prices, nil
+	}
+	if prices, ok := t.Regions[t.DefaultRegion]; ok {
+		return t.DefaultRegion, prices, nil
+	}
+	return "", nil, fmt.Errorf("no price of region %s", region)
+}
+
+// billableBytes returns size billed for objects of usage stored in class of price.
+func (p *ClassPrice) billableBytes(u *ClassUsage) int64 {
+	bytes := u.Bytes + u.Count*p.OverheadBytes
+	if p.MinObjectSize > 0 {
+		bytes += u.Small*p.MinObjectSize - u.SmallBytes
+	}
+	return bytes
+}
+
+func (p *ClassPrice) monthly(billable int64) float64 {
+	return float64(billable) / bytesPerGB * p.PerGBMonth
+}
+
+// CostLine is estimated monthly cost of objects in a storage class.
+type CostLine struct {
+	Class         string
+	Bytes         int64
+	BillableBytes int64
+	Count         int64
+	Monthly       float64
+	// Unknown is set for class not in price table, not included in the total.
+	Unknown bool
+}
+
+// ClassChange is projected cost of moving all objects to a storage class.
+type ClassChange struct {
+	Class   string
+	Monthly float64
+	Savings float64
+	// Transition is one time price of lifecycle transition requests.
+	Transition float64
+	// MinimumCharge is charged at least when objects are deleted within MinDays.
+	MinDays       int
+	MinimumCharge float64
+}
+
+// CostEstimate is monthly storage cost of usage with projections of changing storage class.
+type CostEstimate struct {
+	Region   string
+	Currency string
+	Lines    []*CostLine
+	Monthly  float64
+	Changes  []*ClassChange
+}
+
+// Estimate estimates monthly storage cost of usage in region.
+func (t *PriceTable) Estimate(region string, usage *DirUsage) (*CostEstimate, error) {
+	region, prices, err := t.prices(region)
+	if err != nil {
+		return nil, err
+	}
+	est := &CostEstimate{Region: region, Currency: t.Currency}
+	for _, class := range usage.ClassNames() {
+		u := usage.Classes[class]
+		line := &CostLine{Class: class, Bytes: u.Bytes, BillableBytes: u.Bytes, Count: u.Count}
+		if price, ok := prices[class]; ok {
+			line.BillableBytes = price.billableBytes(u)
+			line.Monthly = price.monthly(line.BillableBytes)
+			est.Monthly += line.Monthly
+		} else {
+			line.Unknown = true
+		}
+		est.Lines = append(est.Lines, line)
+	}
+
+	// classes without price are left out of projections as well as of est.Monthly
+	for class, price := range prices {
+		change := &ClassChange{Class: class, MinDays: price.MinDays}
+		for current, u := range usage.Classes {
+			if _, ok := prices[current]; !ok {
+				continue
+			}
+			change.Monthly += price.monthly(price.billableBytes(u))
+			if current != class {
+				change.Transition += float64(u.Count) / 1000 * price.TransitionPer1000
+			}
+		}
+		change.Savings = est.Monthly - change.Monthly
+		change.MinimumCharge = change.Monthly * float64(price.MinDays) / 30
+		est.Changes = append(est.Changes, change)
+	}
+	sort.Slice(est.Changes, func(i, j int) bool {
+		return est.Changes[i].Monthly < est.Changes[j].Monthly
+	})
+	return est, nil
+}
+
+// BucketRegion returns region where bucket of profile is.
+func BucketRegion(profile, bucket string) (string, error) {
+	ctx, cancelFn := context.WithTimeout(context.Background(), RequestTimeout)
+	defer cancelFn()
+	out, err := getS3ClientFor(profile).GetBucketLocationWithContext(ctx, &s3.GetBucketLocationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed get bucket location, %v", err)
+	}
+	switch region := aws.StringValue(out.LocationConstraint); region {
+	case "":
+		return "us-east-1", nil
+	case "EU":
+		return "eu-west-1", nil
+	default:
+		return region, nil
+	}
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEstimateMinimumSize(t *testing.T) {
+	u := NewDirUsage()
+	u.add("STANDARD_IA", 1024)
+	u.add("STANDARD_IA", 1024*1024)
+	est, err := DefaultPriceTable().Estimate("us-east-1", u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := int64(SmallObjectSize + 1024*1024)
+	if got := est.Lines[0].BillableBytes; got != want {
+		t.Errorf("billable bytes = %d, want %d", got, want)
+	}
+}
+
+func TestEstimateUnknownRegionAndClass(t *testing.T) {
+	u := NewDirUsage()
+	u.add("STANDARD", bytesPerGB)
+	u.add("UNKNOWN", bytesPerGB)
+	est, err := DefaultPriceTable().Estimate("ap-northeast-1", u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if est.Region != "us-east-1" {
+		t.Errorf("region = %s, want default region", est.Region)
+	}
+	if math.Abs(est.Monthly-0.023) > 1e-6 {
+		t.Errorf("monthly = %f, want 0.023", est.Monthly)
+	}
+	for _, l := range est.Lines {
+		if l.Unknown != (l.Class == "UNKNOWN") {
+			t.Errorf("unknown of %s = %v", l.Class, l.Unknown)
+		}
+	}
+	// projections compare the same objects as the total
+	for _, c := range est.Changes {
+		if c.Class == "STANDARD" && math.Abs(c.Savings) > 1e-9 {
+			t.Errorf("savings of current class = %f", c.Savings)
+		}
+	}
+}
+
+func TestEstimateChanges(t *testing.T) {
+	u := NewDirUsage()
+	for i := 0; i < 1000; i++ {
+		u.add("STANDARD", bytesPerGB/1000)
+	}
+	est, err := DefaultPriceTable().Estimate("us-east-1", u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := map[string]*ClassChange{}
+	for i, c := range est.Changes {
+		changes[c.Class] = c
+		if i > 0 && est.Changes[i-1].Monthly > c.Monthly {
+			t.Errorf("changes are not sorted by monthly cost")
+		}
+	}
+	if c := changes["STANDARD"]; c.Savings != 0 || c.Transition != 0 {
+		t.Errorf("change to current class = %+v", c)
+	}
+	ia := changes["STANDARD_IA"]
+	if math.Abs(ia.Savings-(0.023-0.0125)) > 1e-6 {
+		t.Errorf("savings of STANDARD_IA = %f", ia.Savings)
+	}
+	if math.Abs(ia.Transition-0.01) > 1e-6 {
+		t.Errorf("transition of STANDARD_IA = %f", ia.Transition)
+	}
+	if ia.MinDays != 30 || math.Abs(ia.MinimumCharge-ia.Monthly) > 1e-6 {
+		t.Errorf("minimum charge of STANDARD_IA = %f/%dd", ia.MinimumCharge, ia.MinDays)
+	}
+}
+
+func TestDirUsageMerge(t *testing.T) {
+	a, b := NewDirUsage(), NewDirUsage()
+	a.add("STANDARD", 100)
+	b.add("STANDARD", 200*1024)
+	b.add("GLACIER", 300)
+	a.Merge(b)
+	if a.Bytes != 100+200*1024+300 || a.Count != 3 {
+		t.Errorf("total = %d B %d objects", a.Bytes, a.Count)
+	}
+	if c := a.Classes["STANDARD"]; c.Count != 2 || c.Small != 1 || c.SmallBytes != 100 {
+		t.Errorf("STANDARD = %+v", c)
+	}
+}
@@ -0,0 +1,277 @@
+package model
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/lighttiger2505/s3tf/internal"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ListingCache persists listings on disk by profile, bucket and prefix.
+// Bucket list is kept with empty bucket.
+type ListingCache struct {
+	Dir string
+}
+
+// NewListingCache returns cache under XDG cache directory.
+func NewListingCache() *ListingCache {
+	return &ListingCache{Dir: filepath.Join(internal.GetXDGCachePath(), "listings")}
+}
+
+type cachedListing struct {
+	Profile  string          `yaml:"profile"`
+	Bucket   string          `yaml:"bucket"`
+	Prefix   string          `yaml:"prefix"`
+	ListedAt time.Time       `yaml:"listed_at"`
+	Objects  []*cachedObject `yaml:"objects"`
+}
+
+type cachedObject struct {
+	Type         S3ObjectType `yaml:"type"`
+	Name         string       `yaml:"name"`
+	Date         *time.Time   `yaml:"date,omitempty"`
+	Size         *int64       `yaml:"size,omitempty"`
+	ETag         string       `yaml:"etag,omitempty"`
+	StorageClass string       `yaml:"storage_class,omitempty"`
+}
+
+func (c *ListingCache) path(profile, bucket, prefix string) string {
+	if profile == "" {
+		profile = "default"
+	}
+	sum := sha1.Sum([]byte(bucket + "/" + prefix))
+	return filepath.Join(c.Dir, profile, hex.EncodeToString(sum[:])+".yml")
+}
+
+// Load returns cached listing and when it was listed. Objects are nil when it is not cached.
+func (c *ListingCache) Load(profile, bucket, prefix string) ([]*S3Object, time.Time, error) {
+	b, err := ioutil.ReadFile(c.path(profile, bucket, prefix))
+	if os.IsNotExist(err) {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	listing := &cachedListing{}
+	if err := yaml.Unmarshal(b, listing); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed unmarshal cached listing, %v", err)
+	}
+	if listing.Profile != profile || listing.Bucket != bucket || listing.Prefix != prefix {
+		return nil, time.Time{}, nil
+	}
+	objects := make([]*S3Object, len(listing.Objects))
+	for i, o := range listing.Objects {
+		objects[i] = &S3Object{
+			ObjType:      o.Type,
+			Name:         o.Name,
+			Date:         o.Date,
+			Size:         o.Size,
+			ETag:         o.ETag,
+			StorageClass: o.StorageClass,
+		}
+	}
+	return objects, listing.ListedAt, nil
+}
+
+func (c *ListingCache) Save(profile, bucket, prefix string, objects []*S3Object, listedAt time.Time) error {
+	listing := &cachedListing{
+		Profile:  profile,
+		Bucket:   bucket,
+		Prefix:   prefix,
+		ListedAt: listedAt,
+		Objects:  make([]*cachedObject, len(objects)),
+	}
+	for i, obj := range objects {
+		listing.Objects[i] = &cachedObject{
+			Type:         obj.ObjType,
+			Name:         obj.Name,
+			Date:         obj.Date,
+			Size:         obj.Size,
+			ETag:         obj.ETag,
+			StorageClass: obj.StorageClass,
+		}
+	}
+	out, err := yaml.Marshal(listing)
+	if err != nil {
+		return fmt.Errorf("failed marshal cached listing, %v", err)
+	}
+
+	path := c.path(profile, bucket, prefix)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed create cache directory, %v", err)
+	}
+	// replace by rename of own temporary file not to leave broken file,
+	// as the same listing can be saved by prefetch and revalidation at once
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed create cached listing, %v", err)
+	}
+	_, err = tmp.Write(out)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed write cached listing, %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed replace cached listing, %v", err)
+	}
+	return nil
+}
+
+type listingKey struct {
+	bucket, prefix string
+}
+
+// CacheLister lists S3 of a profile saving listings to ListingCache. It serves cached listings
+// when S3 cannot be reached, and before listing S3 while PreferCache is set for fast startup.
+type CacheLister struct {
+	*S3Lister
+	Cache       *ListingCache
+	PreferCache bool
+	// mu guards offline and served, listed in background too.
+	mu      sync.Mutex
+	offline bool
+	// served is listings served from cache waiting to be marked on nodes.
+	served map[listingKey]time.Time
+}
+
+func NewCacheLister(profile string, cache *ListingCache) *CacheLister {
+	return &CacheLister{
+		S3Lister: NewS3Lister(profile),
+		Cache:    cache,
+		served:   map[listingKey]time.Time{},
+	}
+}
+
+func (l *CacheLister) ListBuckets() []*S3Object {
+	return l.list("", "")
+}
+
+func (l *CacheLister) ListObjects(bucket, prefix string) []*S3Object {
+	return l.list(bucket, prefix)
+}
+
+func (l *CacheLister) FetchBuckets() ([]*S3Object, error) {
+	return l.fetch("", "", true)
+}
+
+func (l *CacheLister) FetchObjects(bucket, prefix string) ([]*S3Object, error) {
+	return l.fetch(bucket, prefix, true)
+}
+
+// Refetch lists S3 without falling back to cache, to revalidate cached listing.
+func (l *CacheLister) Refetch(bucket, prefix string) ([]*S3Object, error) {
+	return l.fetch(bucket, prefix, false)
+}
+
+// Offline reports whether the last request failed to reach S3.
+func (l *CacheLister) Offline() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.offline
+}
+
+func (l *CacheLister) list(bucket, prefix string) []*S3Object {
+	objects, err := l.fetch(bucket, prefix, true)
+	if err != nil {
+		log.Printf("failed list s3://%s/%s, %v", bucket, prefix, err)
+		if bucket == "" {
+			return []*S3Object{}
+		}
+		return []*S3Object{NewS3Object(PreDir, "..", nil, nil)}
+	}
+	return objects
+}
+
+func (l *CacheLister) fetch(bucket, prefix string, useCache bool) ([]*S3Object, error) {
+	if useCache && l.PreferCache {
+		if objects, ok := l.cached(bucket, prefix); ok {
+			return objects, nil
+		}
+	}
+
+	var objects []*S3Object
+	var err error
+	if bucket == "" {
+		objects, err = l.S3Lister.FetchBuckets()
+	} else {
+		objects, err = l.S3Lister.FetchObjects(bucket, prefix)
+	}
+	if err == nil {
+		l.mu.Lock()
+		l.offline = false
+		delete(l.served, listingKey{bucket, prefix})
+		l.mu.Unlock()
+		if err := l.Cache.Save(l.Profile, bucket, prefix, objects, time.Now()); err != nil {
+			log.Printf("failed save listing cache, %v", err)
+		}
+		return objects, nil
+	}
+
+	// corehandlers report network failure by "RequestError", not by constant in this SDK version
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "RequestError" {
+		l.mu.Lock()
+		l.offline = true
+		l.mu.Unlock()
+	}
+	if useCache {
+		if objects, ok := l.cached(bucket, prefix); ok {
+			return objects, nil
+		}
+	}
+	return nil, err
+}
+
+func (l *CacheLister) cached(bucket, prefix string) ([]*S3Object, bool) {
+	objects, listedAt, err := l.Cache.Load(l.Profile, bucket, prefix)
+	if err != nil {
+		log.Printf("failed load listing cache, %v", err)
+		return nil, false
+	}
+	if objects == nil {
+		return nil, false
+	}
+	l.mu.Lock()
+	l.served[listingKey{bucket, prefix}] = listedAt
+	l.mu.Unlock()
+	return objects, true
+}
+
+// MarkCached marks nodes listed from cache under root node with the time of cached listing,
+// and invalidates them to be revalidated.
+func (l *CacheLister) MarkCached(root *Node) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.served) == 0 {
+		return
+	}
+	l.markCached("", root)
+}
+
+func (l *CacheLister) markCached(bucket string, n *Node) {
+	key := listingKey{bucket, n.Prefix()}
+	if listedAt, ok := l.served[key]; ok {
+		delete(l.served, key)
+		n.Invalidate()
+		n.CachedAt = listedAt
+	}
+	for _, child := range n.children {
+		childBucket := bucket
+		if n.IsRoot() {
+			childBucket = child.Key
+		}
+		l.markCached(childBucket, child)
+	}
+}
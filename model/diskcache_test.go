@@ -0,0 +1,116 @@
+package model
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestListingCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s3tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cache := &ListingCache{Dir: dir}
+
+	objects, _, err := cache.Load("prod", "bucket", "logs/")
+	if err != nil || objects != nil {
+		t.Fatalf("want no cache, %v %v", objects, err)
+	}
+
+	date := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	size := int64(10)
+	want := []*S3Object{
+		NewS3Object(PreDir, "..", nil, nil),
+		NewS3Object(Dir, "logs/2020/", nil, nil),
+		{ObjType: Object, Name: "logs/a.txt", Date: &date, Size: &size, ETag: `"etag"`, StorageClass: "STANDARD"},
+	}
+	listedAt := time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)
+	if err := cache.Save("prod", "bucket", "logs/", want, listedAt); err != nil {
+		t.Fatal(err)
+	}
+
+	got, gotListedAt, err := cache.Load("prod", "bucket", "logs/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded %v, want %v", got, want)
+	}
+	if !gotListedAt.Equal(listedAt) {
+		t.Errorf("listed at %v, want %v", gotListedAt, listedAt)
+	}
+
+	// other profile has own cache
+	if objects, _, _ := cache.Load("dev", "bucket", "logs/"); objects != nil {
+		t.Errorf("want no cache of other profile, %v", objects)
+	}
+}
+
+func TestListingCacheConcurrentSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s3tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cache := &ListingCache{Dir: dir}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			objects := []*S3Object{NewS3Object(Dir, fmt.Sprintf("logs/%d/", i), nil, nil)}
+			errs <- cache.Save("prod", "bucket", "logs/", objects, time.Now())
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if objects, _, err := cache.Load("prod", "bucket", "logs/"); err != nil || len(objects) != 1 {
+		t.Errorf("loaded %v, %v", objects, err)
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(dir, "prod", "*.tmp")); len(tmps) != 0 {
+		t.Errorf("temporary files are left, %v", tmps)
+	}
+}
+
+func TestCacheListerMarkCached(t *testing.T) {
+	l := &CacheLister{served: map[listingKey]time.Time{}}
+	listedAt := time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)
+	l.served[listingKey{"", ""}] = listedAt
+	l.served[listingKey{"bucket", "logs/"}] = listedAt
+
+	root := NewNode("", nil, nil)
+	bucket := NewNode("bucket", root, nil)
+	root.AddChild("bucket", bucket)
+	logs := NewNode("logs/", bucket, nil)
+	bucket.AddChild("logs/", logs)
+
+	l.MarkCached(root)
+	if !root.CachedAt.Equal(listedAt) || !logs.CachedAt.Equal(listedAt) || !bucket.CachedAt.IsZero() {
+		t.Errorf("cached at root:%v bucket:%v logs:%v", root.CachedAt, bucket.CachedAt, logs.CachedAt)
+	}
+	if !root.IsStale(0) || !logs.IsStale(0) || bucket.IsStale(0) {
+		t.Error("want cached nodes stale")
+	}
+	if len(l.served) != 0 {
+		t.Errorf("served left, %v", l.served)
+	}
+
+	logs.Refresh(nil)
+	if !logs.CachedAt.IsZero() {
+		t.Error("want cached at cleared by refresh")
+	}
+}
@@ -0,0 +1,271 @@
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// EditFile is a local copy of object for editing, remembering the version it was made from.
+// Compressed object is edited decompressed, and compressed again with the same codec on save.
+type EditFile struct {
+	Bucket string
+	Key    string
+	// Path is file to edit, BasePath keeps the downloaded contents for merge.
+	Path       string
+	BasePath   string
+	ETag       string
+	Encoding   string
+	Properties *ObjectProperties
+	hash       []byte
+	tempDir    string
+	remote     *objectVersion
+}
+
+// objectVersion is a version of object downloaded to local file.
+type objectVersion struct {
+	path     string
+	etag     string
+	encoding string
+	props    *ObjectProperties
+}
+
+// DownloadForEdit downloads object into temporary file.
+func DownloadForEdit(bucket, key string) (*EditFile, error) {
+	tempDir, err := ioutil.TempDir("", "s3tf")
+	if err != nil {
+		return nil, err
+	}
+	baseDir := filepath.Join(tempDir, "base")
+	if err := os.Mkdir(baseDir, 0700); err != nil {
+		return nil, err
+	}
+
+	v, err := downloadVersion(bucket, key, baseDir)
+	if err != nil {
+		return nil, err
+	}
+	ef := &EditFile{
+		Bucket:     bucket,
+		Key:        key,
+		Path:       filepath.Join(tempDir, filepath.Base(v.path)),
+		BasePath:   v.path,
+		Encoding:   v.encoding,
+		Properties: v.props,
+		tempDir:    tempDir,
+	}
+	if err := ef.rebase(ef.BasePath, v.etag); err != nil {
+		return nil, err
+	}
+	if err := copyFile(ef.BasePath, ef.Path); err != nil {
+		return nil, err
+	}
+	return ef, nil
+}
+
+// DownloadDecoded downloads object into dir decompressing it, and returns path of the file.
+func DownloadDecoded(bucket, key, dir string) (string, error) {
+	v, err := downloadVersion(bucket, key, dir)
+	if err != nil {
+		return "", err
+	}
+	return v.path, nil
+}
+
+// downloadVersion writes decompressed object into dir, and returns the written version.
+func downloadVersion(bucket, key, dir string) (*objectVersion, error) {
+	client := getS3Client()
+
+	ctx := context.Background()
+	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
+	defer cancelFn()
+
+	result, err := client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed get object, %v", err)
+	}
+	defer result.Body.Close()
+
+	v := &objectVersion{
+		etag:  aws.StringValue(result.ETag),
+		props: newObjectProperties(result),
+	}
+	body := bufio.NewReader(result.Body)
+	head, _ := body.Peek(len(zstdMagic))
+	v.encoding = DetectEncoding(key, v.props.ContentEncoding, head)
+	v.path = filepath.Join(dir, Filename(key))
+	if v.encoding != "" {
+		v.path = filepath.Join(dir, Filename(TrimEncodingSuffix(key)))
+	}
+
+	r, err := NewDecoder(v.encoding, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed decompress %s, %v", v.encoding, err)
+	}
+	defer r.Close()
+	f, err := os.Create(v.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, r); err != nil {
+		return nil, fmt.Errorf("failed download, %v", err)
+	}
+
+	if aws.Int64Value(result.TagCount) > 0 {
+		if v.props.Tags, err = GetTags(bucket, key); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// Changed reports whether edit file differs from the version it was made from.
+func (ef *EditFile) Changed() (bool, error) {
+	hash, err := hashFile(ef.Path)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(hash, ef.hash), nil
+}
+
+// Conflict reports whether object has been replaced after it was downloaded.
+func (ef *EditFile) Conflict() (bool, error) {
+	head, err := Head(ef.Bucket, ef.Key)
+	if err != nil {
+		return false, err
+	}
+	return aws.StringValue(head.ETag) != ef.ETag, nil
+}
+
+// FetchRemote downloads current version of object next to edit file, and returns its path.
+func (ef *EditFile) FetchRemote() (string, error) {
+	dir := filepath.Join(ef.tempDir, "remote")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	v, err := downloadVersion(ef.Bucket, ef.Key, dir)
+	if err != nil {
+		return "", err
+	}
+	ef.remote = v
+	return v.path, nil
+}
+
+// Rebase makes remote version fetched by FetchRemote the base of edit,
+// after its changes have been merged into edit file.
+func (ef *EditFile) Rebase() error {
+	if ef.remote == nil {
+		return fmt.Errorf("remote version is not fetched")
+	}
+	if err := copyFile(ef.remote.path, ef.BasePath); err != nil {
+		return err
+	}
+	ef.Encoding = ef.remote.encoding
+	ef.Properties = ef.remote.props
+	return ef.rebase(ef.BasePath, ef.remote.etag)
+}
+
+func (ef *EditFile) rebase(path, etag string) error {
+	hash, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+	ef.hash = hash
+	ef.ETag = etag
+	return nil
+}
+
+// Save uploads edit file to the object.
+func (ef *EditFile) Save() error {
+	f, err := ef.compress()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	result, err := Update(ef.Bucket, ef.Key, f, ef.Properties)
+	if err != nil {
+		return err
+	}
+	return ef.rebase(ef.Path, aws.StringValue(result.ETag))
+}
+
+// compress opens edit file to upload, compressing it when the object is compressed.
+func (ef *EditFile) compress() (*os.File, error) {
+	src, err := os.Open(ef.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed open edited file, %v", err)
+	}
+	if ef.Encoding == "" {
+		return src, nil
+	}
+	defer src.Close()
+
+	dst, err := os.Create(filepath.Join(ef.tempDir, "upload"))
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewEncoder(ef.Encoding, dst)
+	if err != nil {
+		dst.Close()
+		return nil, err
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		dst.Close()
+		return nil, fmt.Errorf("failed compress %s, %v", ef.Encoding, err)
+	}
+	if err := w.Close(); err != nil {
+		dst.Close()
+		return nil, fmt.Errorf("failed compress %s, %v", ef.Encoding, err)
+	}
+	if _, err := dst.Seek(0, io.SeekStart); err != nil {
+		dst.Close()
+		return nil, err
+	}
+	return dst, nil
+}
+
+// Close removes temporary files.
+func (ef *EditFile) Close() error {
+	return os.RemoveAll(ef.tempDir)
+}
+
+func hashFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, in)
+	return err
+}
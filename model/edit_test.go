@@ -0,0 +1,122 @@
+package model
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// fakeS3 serves objects stored with Content-Encoding: gzip, recording uploaded ones.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	puts    map[string]*http.Request
+	bodies  map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	switch r.Method {
+	case http.MethodPut:
+		b, _ := ioutil.ReadAll(r.Body)
+		f.puts[key], f.bodies[key] = r, b
+		w.Header().Set("ETag", `"uploaded"`)
+	case http.MethodGet, http.MethodHead:
+		body, ok := f.objects[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"stored"`)
+		w.Write(body)
+	}
+}
+
+func gzipBytes(t *testing.T, s string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(s))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// useFakeS3 makes package level functions request f until returned func is called.
+func useFakeS3(t *testing.T, f *fakeS3) func() {
+	srv := httptest.NewServer(f)
+	name := "fake-" + t.Name()
+	sessionMutex.Lock()
+	sessions[name] = session.New(&aws.Config{
+		HTTPClient:       httpClient,
+		Credentials:      credentials.NewStaticCredentials("access_key", "secret_key", ""),
+		Endpoint:         aws.String(srv.URL),
+		Region:           aws.String("us-east-1"),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	sessionMutex.Unlock()
+	old := currentProfile()
+	SetProfile(name)
+	return func() {
+		SetProfile(old)
+		sessionMutex.Lock()
+		delete(sessions, name)
+		sessionMutex.Unlock()
+		srv.Close()
+	}
+}
+
+func TestEditContentEncodingRoundTrip(t *testing.T) {
+	f := &fakeS3{
+		objects: map[string][]byte{},
+		puts:    map[string]*http.Request{},
+		bodies:  map[string][]byte{},
+	}
+	defer useFakeS3(t, f)()
+
+	for _, key := range []string{"data.json", "data.json.gz"} {
+		f.objects[key] = gzipBytes(t, `{"a": 1}`)
+
+		ef, err := DownloadForEdit("bucket", key)
+		if err != nil {
+			t.Fatalf("%s: %v", key, err)
+		}
+		defer ef.Close()
+		if ef.Encoding != EncodingGzip || ef.Properties.ContentEncoding != "gzip" {
+			t.Errorf("%s: encoding = %q, content encoding = %q", key, ef.Encoding, ef.Properties.ContentEncoding)
+		}
+		if b, _ := ioutil.ReadFile(ef.Path); string(b) != `{"a": 1}` {
+			t.Errorf("%s: edit file = %q", key, b)
+		}
+
+		if err := ioutil.WriteFile(ef.Path, []byte(`{"a": 2}`), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := ef.Save(); err != nil {
+			t.Fatalf("%s: %v", key, err)
+		}
+		if got := f.puts[key].Header.Get("Content-Encoding"); got != "gzip" {
+			t.Errorf("%s: uploaded with Content-Encoding %q", key, got)
+		}
+		r, err := gzip.NewReader(bytes.NewReader(f.bodies[key]))
+		if err != nil {
+			t.Fatalf("%s: uploaded body is not gzip, %v", key, err)
+		}
+		if b, _ := ioutil.ReadAll(r); string(b) != `{"a": 2}` {
+			t.Errorf("%s: uploaded %q", key, b)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	EncodingGzip = "gzip"
+	EncodingZstd = "zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// DetectEncoding returns compression codec of object from Content-Encoding, key suffix or magic bytes.
+func DetectEncoding(key, contentEncoding string, head []byte) string {
+	switch {
+	case strings.Contains(contentEncoding, EncodingGzip):
+		return EncodingGzip
+	case strings.Contains(contentEncoding, EncodingZstd):
+		return EncodingZstd
+	case strings.HasSuffix(key, ".gz"):
+		return EncodingGzip
+	case strings.HasSuffix(key, ".zst"):
+		return EncodingZstd
+	case bytes.HasPrefix(head, gzipMagic):
+		return EncodingGzip
+	case bytes.HasPrefix(head, zstdMagic):
+		return EncodingZstd
+	}
+	return ""
+}
+
+// TrimEncodingSuffix removes compression suffix from key, e.g. "app.json.gz" to "app.json".
+func TrimEncodingSuffix(key string) string {
+	for _, suffix := range []string{".gz", ".zst"} {
+		if strings.HasSuffix(key, suffix) {
+			return strings.TrimSuffix(key, suffix)
+		}
+	}
+	return key
+}
+
+// NewDecoder wraps reader by decompressor of encoding.
+func NewDecoder(encoding string, r io.Reader) (io.ReadCloser, error) {
+	switch encoding {
+	case EncodingGzip:
+		return gzip.NewReader(r)
+	case EncodingZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	}
+	return io.NopCloser(r), nil
+}
+
+// NewEncoder wraps writer by compressor of encoding.
+func NewEncoder(encoding string, w io.Writer) (io.WriteCloser, error) {
+	switch encoding {
+	case EncodingGzip:
+		return gzip.NewWriter(w), nil
+	case EncodingZstd:
+		return zstd.NewWriter(w)
+	}
+	return nopWriteCloser{w}, nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		key             string
+		contentEncoding string
+		head            []byte
+		want            string
+	}{
+		{"logs/app.log", "", []byte("hello"), ""},
+		{"logs/app.log", "gzip", []byte("hello"), EncodingGzip},
+		{"logs/app.log.gz", "", []byte("hello"), EncodingGzip},
+		{"logs/app.log.zst", "", []byte("hello"), EncodingZstd},
+		{"logs/app.log", "", []byte{0x1f, 0x8b, 0x08}, EncodingGzip},
+		{"logs/app.log", "", []byte{0x28, 0xb5, 0x2f, 0xfd, 0x00}, EncodingZstd},
+	}
+	for _, tt := range tests {
+		got := DetectEncoding(tt.key, tt.contentEncoding, tt.head)
+		if got != tt.want {
+			t.Errorf("DetectEncoding(%q, %q) want %q, but %q", tt.key, tt.contentEncoding, tt.want, got)
+		}
+	}
+}
+
+func TestEncoderRoundTrip(t *testing.T) {
+	want := []byte("key: value\n")
+	for _, encoding := range []string{"", EncodingGzip, EncodingZstd} {
+		var buf bytes.Buffer
+		w, err := NewEncoder(encoding, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(want)
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if got := DetectEncoding("config.yml", "", buf.Bytes()); got != encoding {
+			t.Errorf("DetectEncoding() = %q, want %q", got, encoding)
+		}
+		r, err := NewDecoder(encoding, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%q: got %q, want %q", encoding, got, want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"path"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatText   Format = "text"
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+	FormatYAML   Format = "yaml"
+	FormatCSV    Format = "csv"
+	FormatTSV    Format = "tsv"
+	FormatSource Format = "source"
+)
+
+var formatByExt = map[string]Format{
+	".json":    FormatJSON,
+	".tfstate": FormatJSON,
+	".ndjson":  FormatNDJSON,
+	".jsonl":   FormatNDJSON,
+	".yaml":    FormatYAML,
+	".yml":     FormatYAML,
+	".csv":     FormatCSV,
+	".tsv":     FormatTSV,
+}
+
+var formatByContentType = map[string]Format{
+	"application/json":          FormatJSON,
+	"application/x-ndjson":      FormatNDJSON,
+	"application/x-yaml":        FormatYAML,
+	"application/yaml":          FormatYAML,
+	"text/yaml":                 FormatYAML,
+	"text/csv":                  FormatCSV,
+	"text/tab-separated-values": FormatTSV,
+}
+
+// languageByExt maps source file extension to the name of language for syntax highlighting.
+var languageByExt = map[string]string{
+	".go":   "go",
+	".py":   "python",
+	".js":   "javascript",
+	".ts":   "javascript",
+	".java": "java",
+	".rb":   "ruby",
+	".sh":   "shell",
+	".bash": "shell",
+	".tf":   "hcl",
+	".hcl":  "hcl",
+	".sql":  "sql",
+	".c":    "c",
+	".h":    "c",
+	".rs":   "rust",
+}
+
+// DetectFormat returns format of object from extension of key, or from Content-Type.
+func DetectFormat(key, contentType string) Format {
+	ext := strings.ToLower(path.Ext(key))
+	if format, ok := formatByExt[ext]; ok {
+		return format
+	}
+	if _, ok := languageByExt[ext]; ok {
+		return FormatSource
+	}
+
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+	if format, ok := formatByContentType[mediaType]; ok {
+		return format
+	}
+	return FormatText
+}
+
+// Language returns language name of source file, or empty string if unknown.
+func Language(key string) string {
+	return languageByExt[strings.ToLower(path.Ext(TrimEncodingSuffix(key)))]
+}
@@ -0,0 +1,168 @@
+package model
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/lighttiger2505/s3tf/internal"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// maxVisits is number of visited locations kept in history file.
+const maxVisits = 500
+
+// maxNavigation is depth of back and forward stacks.
+const maxNavigation = 100
+
+// Navigation is back and forward stacks of locations like a browser.
+type Navigation struct {
+	back    []*Location
+	forward []*Location
+}
+
+// Push records loc left by a jump, and drops forward locations.
+func (n *Navigation) Push(loc *Location) {
+	if len(n.back) > 0 && sameLocation(n.back[len(n.back)-1], loc) {
+		return
+	}
+	n.back = appendLimited(n.back, loc)
+	n.forward = nil
+}
+
+// Back returns location to go back from current, nil when there is none.
+func (n *Navigation) Back(current *Location) *Location {
+	if len(n.back) == 0 {
+		return nil
+	}
+	loc := n.back[len(n.back)-1]
+	n.back = n.back[:len(n.back)-1]
+	n.forward = appendLimited(n.forward, current)
+	return loc
+}
+
+// Forward returns location gone back from, nil when there is none.
+func (n *Navigation) Forward(current *Location) *Location {
+	if len(n.forward) == 0 {
+		return nil
+	}
+	loc := n.forward[len(n.forward)-1]
+	n.forward = n.forward[:len(n.forward)-1]
+	n.back = appendLimited(n.back, current)
+	return loc
+}
+
+func appendLimited(locs []*Location, loc *Location) []*Location {
+	locs = append(locs, loc)
+	if len(locs) > maxNavigation {
+		locs = locs[len(locs)-maxNavigation:]
+	}
+	return locs
+}
+
+func sameLocation(a, b *Location) bool {
+	return *a == *b
+}
+
+// Visit is a location visited, with the last time and number of visits.
+type Visit struct {
+	Location `yaml:",inline"`
+	Time     time.Time `yaml:"time"`
+	Count    int       `yaml:"count"`
+}
+
+// Score is frecency of the visit, count weighted by how recent the last visit is.
+func (v *Visit) Score(now time.Time) float64 {
+	age := now.Sub(v.Time)
+	weight := 0.25
+	switch {
+	case age < time.Hour:
+		weight = 4
+	case age < 24*time.Hour:
+		weight = 2
+	case age < 7*24*time.Hour:
+		weight = 1
+	case age < 30*24*time.Hour:
+		weight = 0.5
+	}
+	return float64(v.Count) * weight
+}
+
+type HistoryFile struct {
+	Items []*Visit `yaml:"items"`
+}
+
+// Visit records loc visited at now.
+func (f *HistoryFile) Visit(loc *Location, now time.Time) {
+	for _, v := range f.Items {
+		if sameLocation(&v.Location, loc) {
+			v.Time = now
+			v.Count++
+			return
+		}
+	}
+	f.Items = append(f.Items, &Visit{Location: *loc, Time: now, Count: 1})
+	if len(f.Items) > maxVisits {
+		// forget the least frecent one
+		ranked := f.Ranked(now)
+		f.Remove(&ranked[len(ranked)-1].Location)
+	}
+}
+
+func (f *HistoryFile) Remove(loc *Location) {
+	for i, v := range f.Items {
+		if sameLocation(&v.Location, loc) {
+			f.Items = append(f.Items[:i], f.Items[i+1:]...)
+			return
+		}
+	}
+}
+
+// Recent returns visits from the latest.
+func (f *HistoryFile) Recent() []*Visit {
+	visits := append([]*Visit{}, f.Items...)
+	sort.SliceStable(visits, func(i, j int) bool {
+		return visits[i].Time.After(visits[j].Time)
+	})
+	return visits
+}
+
+// Ranked returns visits from the most frecent.
+func (f *HistoryFile) Ranked(now time.Time) []*Visit {
+	visits := f.Recent()
+	sort.SliceStable(visits, func(i, j int) bool {
+		return visits[i].Score(now) > visits[j].Score(now)
+	})
+	return visits
+}
+
+func getHistoryFilePath() string {
+	return filepath.Join(internal.GetXDGConfigPath(), "history.yml")
+}
+
+// LoadHistoryFile returns visited locations of past sessions, empty when there is none.
+func LoadHistoryFile() (*HistoryFile, error) {
+	b, err := ioutil.ReadFile(getHistoryFilePath())
+	if os.IsNotExist(err) {
+		return &HistoryFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	f := &HistoryFile{}
+	if err := yaml.Unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("Failed unmarshal yaml. Error: %s", err.Error())
+	}
+	return f, nil
+}
+
+func SaveHistoryFile(f *HistoryFile) error {
+	out, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("Failed marshal config. Error: %v", err.Error())
+	}
+	return ioutil.WriteFile(getHistoryFilePath(), out, 0644)
+}
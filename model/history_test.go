@@ -0,0 +1,67 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNavigation(t *testing.T) {
+	a := &Location{Bucket: "a"}
+	b := &Location{Bucket: "b"}
+	c := &Location{Bucket: "c"}
+
+	n := &Navigation{}
+	n.Push(a)
+	n.Push(b)
+	// now at c
+	if got := n.Back(c); got != b {
+		t.Fatalf("back %v, want %v", got, b)
+	}
+	if got := n.Back(b); got != a {
+		t.Fatalf("back %v, want %v", got, a)
+	}
+	if got := n.Back(a); got != nil {
+		t.Fatalf("back %v, want nil", got)
+	}
+	if got := n.Forward(a); got != b {
+		t.Fatalf("forward %v, want %v", got, b)
+	}
+
+	// jump drops forward locations
+	n.Push(b)
+	if got := n.Forward(c); got != nil {
+		t.Fatalf("forward %v, want nil", got)
+	}
+}
+
+func TestHistoryFileRanked(t *testing.T) {
+	now := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	f := &HistoryFile{}
+	old := &Location{Bucket: "bucket", Key: "old/"}
+	for i := 0; i < 5; i++ {
+		f.Visit(old, now.AddDate(0, 0, -8))
+	}
+	often := &Location{Bucket: "bucket", Key: "often/"}
+	for i := 0; i < 3; i++ {
+		f.Visit(often, now.Add(-2*time.Hour))
+	}
+	recent := &Location{Bucket: "bucket", Key: "recent/"}
+	f.Visit(recent, now)
+
+	if got := len(f.Items); got != 3 {
+		t.Fatalf("visits %d, want 3", got)
+	}
+	want := []string{"recent/", "often/", "old/"}
+	for i, v := range f.Recent() {
+		if v.Key != want[i] {
+			t.Errorf("recent[%d] %s, want %s", i, v.Key, want[i])
+		}
+	}
+	// often 3*2, old 5*0.5, recent 1*4
+	want = []string{"often/", "recent/", "old/"}
+	for i, v := range f.Ranked(now) {
+		if v.Key != want[i] {
+			t.Errorf("ranked[%d] %s, want %s", i, v.Key, want[i])
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/lighttiger2505/s3tf/internal"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Location is a place to open, prefix of bucket on S3 of profile or on local filesystem.
+type Location struct {
+	Profile string `yaml:"profile,omitempty"`
+	Bucket  string `yaml:"bucket"`
+	Key     string `yaml:"key"`
+	Local   bool   `yaml:"local,omitempty"`
+}
+
+func (l *Location) String() string {
+	if l.Local {
+		return LocalPath(l.Bucket, l.Key)
+	}
+	if l.Bucket == "" {
+		return "s3://"
+	}
+	return S3URL(l.Bucket, l.Key)
+}
+
+// ParseLocation makes location from s3:// URL.
+func ParseLocation(s, profile string) (*Location, error) {
+	bucket, key, err := ParseS3URL(s)
+	if err != nil {
+		return nil, err
+	}
+	return &Location{Profile: profile, Bucket: bucket, Key: key}, nil
+}
+
+func getLastLocationPath() string {
+	return filepath.Join(internal.GetXDGConfigPath(), "last.yml")
+}
+
+// LoadLastLocation returns location where the last session ended, nil when there is none.
+func LoadLastLocation() (*Location, error) {
+	b, err := ioutil.ReadFile(getLastLocationPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	loc := &Location{}
+	if err := yaml.Unmarshal(b, loc); err != nil {
+		return nil, fmt.Errorf("Failed unmarshal yaml. Error: %s", err.Error())
+	}
+	return loc, nil
+}
+
+func SaveLastLocation(loc *Location) error {
+	out, err := yaml.Marshal(loc)
+	if err != nil {
+		return fmt.Errorf("Failed marshal config. Error: %v", err.Error())
+	}
+	return ioutil.WriteFile(getLastLocationPath(), out, 0644)
+}
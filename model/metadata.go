@@ -0,0 +1,307 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	maxTags           = 10
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+	maxMetadataSize   = 2048
+)
+
+// EditableProperties is the part of object properties edited as YAML document.
+type EditableProperties struct {
+	ContentType        string            `yaml:"content_type"`
+	CacheControl       string            `yaml:"cache_control"`
+	ContentDisposition string            `yaml:"content_disposition"`
+	Metadata           map[string]string `yaml:"metadata"`
+	Tags               map[string]string `yaml:"tags"`
+}
+
+func (props *ObjectProperties) Editable() *EditableProperties {
+	return &EditableProperties{
+		ContentType:        props.ContentType,
+		CacheControl:       props.CacheControl,
+		ContentDisposition: props.ContentDisposition,
+		Metadata:           copyMap(props.Metadata),
+		Tags:               copyMap(props.Tags),
+	}
+}
+
+// MarshalEditable writes properties as YAML document, preceded by comment lines.
+func MarshalEditable(ep *EditableProperties, comments ...string) ([]byte, error) {
+	b, err := yaml.Marshal(ep)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for _, c := range comments {
+		fmt.Fprintf(&buf, "# %s\n", c)
+	}
+	buf.Write(b)
+	return buf.Bytes(), nil
+}
+
+// ParseEditable reads YAML document of properties and validates it.
+func ParseEditable(b []byte) (*EditableProperties, error) {
+	ep := &EditableProperties{}
+	if err := yaml.UnmarshalStrict(b, ep); err != nil {
+		return nil, err
+	}
+	if err := ep.Validate(); err != nil {
+		return nil, err
+	}
+	return ep, nil
+}
+
+// Validate checks properties against restrictions of S3.
+func (ep *EditableProperties) Validate() error {
+	if ep.ContentType != "" {
+		if _, _, err := mime.ParseMediaType(ep.ContentType); err != nil {
+			return fmt.Errorf("invalid content_type %q, %v", ep.ContentType, err)
+		}
+	}
+	size := 0
+	for k, v := range ep.Metadata {
+		if k == "" || strings.IndexFunc(k, func(r rune) bool { return !isTokenRune(r) }) >= 0 {
+			return fmt.Errorf("invalid metadata key %q", k)
+		}
+		if strings.ContainsAny(v, "\r\n") {
+			return fmt.Errorf("invalid metadata value of %q, line break is not allowed", k)
+		}
+		size += len(k) + len(v)
+	}
+	if size > maxMetadataSize {
+		return fmt.Errorf("metadata is %d bytes, must be at most %d bytes", size, maxMetadataSize)
+	}
+	if len(ep.Tags) > maxTags {
+		return fmt.Errorf("%d tags, must be at most %d", len(ep.Tags), maxTags)
+	}
+	for k, v := range ep.Tags {
+		if k == "" || utf8.RuneCountInString(k) > maxTagKeyLength {
+			return fmt.Errorf("invalid tag key %q, must be 1 to %d characters", k, maxTagKeyLength)
+		}
+		if utf8.RuneCountInString(v) > maxTagValueLength {
+			return fmt.Errorf("invalid tag value of %q, must be at most %d characters", k, maxTagValueLength)
+		}
+		if strings.HasPrefix(strings.ToLower(k), "aws:") {
+			return fmt.Errorf("invalid tag key %q, aws: prefix is reserved", k)
+		}
+	}
+	return nil
+}
+
+// isTokenRune reports whether r is allowed in HTTP header name.
+func isTokenRune(r rune) bool {
+	if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+		return true
+	}
+	return strings.ContainsRune("!#$%&'*+-.^_`|~", r)
+}
+
+// PropertiesDiff is change of editable properties, which can be applied to other objects.
+type PropertiesDiff struct {
+	// Headers has changed values of content_type, cache_control and content_disposition.
+	Headers        map[string]string
+	SetMetadata    map[string]string
+	RemoveMetadata []string
+	SetTags        map[string]string
+	RemoveTags     []string
+}
+
+// DiffEditable returns change from before to after.
+func DiffEditable(before, after *EditableProperties) *PropertiesDiff {
+	d := &PropertiesDiff{Headers: map[string]string{}}
+	if before.ContentType != after.ContentType {
+		d.Headers["content_type"] = after.ContentType
+	}
+	if before.CacheControl != after.CacheControl {
+		d.Headers["cache_control"] = after.CacheControl
+	}
+	if before.ContentDisposition != after.ContentDisposition {
+		d.Headers["content_disposition"] = after.ContentDisposition
+	}
+	d.SetMetadata, d.RemoveMetadata = diffMap(before.Metadata, after.Metadata)
+	d.SetTags, d.RemoveTags = diffMap(before.Tags, after.Tags)
+	return d
+}
+
+func diffMap(before, after map[string]string) (map[string]string, []string) {
+	set := map[string]string{}
+	for k, v := range after {
+		if old, ok := before[k]; !ok || old != v {
+			set[k] = v
+		}
+	}
+	var remove []string
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			remove = append(remove, k)
+		}
+	}
+	sort.Strings(remove)
+	return set, remove
+}
+
+func (d *PropertiesDiff) Empty() bool {
+	return !d.headersChanged() && !d.tagsChanged()
+}
+
+func (d *PropertiesDiff) headersChanged() bool {
+	return len(d.Headers) > 0 || len(d.SetMetadata) > 0 || len(d.RemoveMetadata) > 0
+}
+
+func (d *PropertiesDiff) tagsChanged() bool {
+	return len(d.SetTags) > 0 || len(d.RemoveTags) > 0
+}
+
+// Apply changes properties by diff.
+func (d *PropertiesDiff) Apply(props *ObjectProperties) {
+	for name, v := range d.Headers {
+		switch name {
+		case "content_type":
+			props.ContentType = v
+		case "cache_control":
+			props.CacheControl = v
+		case "content_disposition":
+			props.ContentDisposition = v
+		}
+	}
+	props.Metadata = applyMap(props.Metadata, d.SetMetadata, d.RemoveMetadata)
+	props.Tags = applyMap(props.Tags, d.SetTags, d.RemoveTags)
+}
+
+func applyMap(m, set map[string]string, remove []string) map[string]string {
+	m = copyMap(m)
+	for k, v := range set {
+		m[k] = v
+	}
+	for _, k := range remove {
+		delete(m, k)
+	}
+	return m
+}
+
+func copyMap(m map[string]string) map[string]string {
+	res := map[string]string{}
+	for k, v := range m {
+		res[k] = v
+	}
+	return res
+}
+
+// GetProperties returns current properties and tags of object.
+func GetProperties(bucket, key string) (*ObjectProperties, error) {
+	head, err := Head(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	return headProperties(bucket, key, head)
+}
+
+// headProperties gets tags of object to make properties with head.
+func headProperties(bucket, key string, head *s3.HeadObjectOutput) (*ObjectProperties, error) {
+	var err error
+	props := &ObjectProperties{
+		ContentType:             aws.StringValue(head.ContentType),
+		CacheControl:            aws.StringValue(head.CacheControl),
+		ContentDisposition:      aws.StringValue(head.ContentDisposition),
+		ContentEncoding:         aws.StringValue(head.ContentEncoding),
+		ContentLanguage:         aws.StringValue(head.ContentLanguage),
+		Expires:                 aws.StringValue(head.Expires),
+		Metadata:                aws.StringValueMap(head.Metadata),
+		StorageClass:            aws.StringValue(head.StorageClass),
+		ServerSideEncryption:    aws.StringValue(head.ServerSideEncryption),
+		SSEKMSKeyID:             aws.StringValue(head.SSEKMSKeyId),
+		WebsiteRedirectLocation: aws.StringValue(head.WebsiteRedirectLocation),
+	}
+	if props.Tags, err = GetTags(bucket, key); err != nil {
+		return nil, err
+	}
+	return props, nil
+}
+
+// ApplyProperties applies diff to object, copying it in place when headers or metadata are changed.
+func ApplyProperties(bucket, key string, d *PropertiesDiff) error {
+	props, err := GetProperties(bucket, key)
+	if err != nil {
+		return err
+	}
+	d.Apply(props)
+	if d.headersChanged() {
+		if err := replaceProperties(bucket, key, props); err != nil {
+			return err
+		}
+	}
+	if d.tagsChanged() {
+		if err := PutTags(bucket, key, props.Tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func replaceProperties(bucket, key string, props *ObjectProperties) error {
+	client := getS3Client()
+
+	ctx := context.Background()
+	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
+	defer cancelFn()
+
+	// copy object onto itself replaces metadata, so put all the properties again
+	put := &s3.PutObjectInput{}
+	props.applyTo(put)
+	_, err := client.CopyObjectWithContext(ctx, &s3.CopyObjectInput{
+		Bucket:                  aws.String(bucket),
+		Key:                     aws.String(key),
+		CopySource:              aws.String(url.PathEscape(bucket + "/" + key)),
+		MetadataDirective:       aws.String(s3.MetadataDirectiveReplace),
+		ContentType:             put.ContentType,
+		CacheControl:            put.CacheControl,
+		ContentDisposition:      put.ContentDisposition,
+		ContentEncoding:         put.ContentEncoding,
+		ContentLanguage:         put.ContentLanguage,
+		Expires:                 put.Expires,
+		Metadata:                put.Metadata,
+		StorageClass:            put.StorageClass,
+		ServerSideEncryption:    put.ServerSideEncryption,
+		SSEKMSKeyId:             put.SSEKMSKeyId,
+		WebsiteRedirectLocation: put.WebsiteRedirectLocation,
+	})
+	if err != nil {
+		return fmt.Errorf("failed copy object, %v", err)
+	}
+	return nil
+}
+
+// PutTags replaces tag set of object.
+func PutTags(bucket, key string, tags map[string]string) error {
+	client := getS3Client()
+
+	ctx := context.Background()
+	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
+	defer cancelFn()
+
+	_, err := client.PutObjectTaggingWithContext(ctx, &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		Tagging: &s3.Tagging{TagSet: tagSet(tags)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed put object tagging, %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEditable(t *testing.T) {
+	tests := []struct {
+		doc     string
+		wantErr bool
+	}{
+		{"content_type: text/html\nmetadata:\n  owner: infra\ntags:\n  env: prod\n", false},
+		{"content_type: text/html\nunknown: value\n", true},
+		{"content_type: ';;'\n", true},
+		{"metadata:\n  bad key: value\n", true},
+		{"tags:\n  aws:createdBy: me\n", true},
+	}
+	for _, tt := range tests {
+		_, err := ParseEditable([]byte(tt.doc))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseEditable(%q) error = %v, wantErr %v", tt.doc, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPropertiesDiffApply(t *testing.T) {
+	before := &EditableProperties{
+		ContentType: "text/plain",
+		Metadata:    map[string]string{"Owner": "infra", "Team": "web"},
+		Tags:        map[string]string{"env": "dev"},
+	}
+	after := &EditableProperties{
+		ContentType: "text/html",
+		Metadata:    map[string]string{"Owner": "infra"},
+		Tags:        map[string]string{"env": "prod", "cost": "web"},
+	}
+	diff := DiffEditable(before, after)
+	if diff.Empty() {
+		t.Fatal("diff is empty")
+	}
+
+	props := &ObjectProperties{
+		ContentType:  "application/json",
+		CacheControl: "no-cache",
+		Metadata:     map[string]string{"Team": "api", "Origin": "batch"},
+		Tags:         map[string]string{"keep": "yes"},
+	}
+	diff.Apply(props)
+	want := &ObjectProperties{
+		ContentType:  "text/html",
+		CacheControl: "no-cache",
+		Metadata:     map[string]string{"Origin": "batch"},
+		Tags:         map[string]string{"keep": "yes", "env": "prod", "cost": "web"},
+	}
+	if !reflect.DeepEqual(props, want) {
+		t.Errorf("Apply() = %+v, want %+v", props, want)
+	}
+	if !DiffEditable(after, after).Empty() {
+		t.Error("diff of same properties is not empty")
+	}
+}
@@ -0,0 +1,232 @@
+package model
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// OutputFormat is format of listing and status written for scripts.
+type OutputFormat string
+
+const (
+	OutputTable OutputFormat = "table"
+	OutputJSON  OutputFormat = "json"
+	OutputCSV   OutputFormat = "csv"
+)
+
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch f := OutputFormat(strings.ToLower(s)); f {
+	case OutputTable, OutputJSON, OutputCSV:
+		return f, nil
+	}
+	return "", fmt.Errorf("invalid output format %q, must be table, json or csv", s)
+}
+
+// OutputFormatOf returns format by extension of path, e.g. ".jsonl" for JSON lines.
+func OutputFormatOf(path string) OutputFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".jsonl", ".ndjson":
+		return OutputJSON
+	case ".csv":
+		return OutputCSV
+	}
+	return OutputTable
+}
+
+// ListingRecord is an entry of listing in output.
+type ListingRecord struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Size         int64  `json:"size"`
+	LastModified string `json:"last_modified,omitempty"`
+	StorageClass string `json:"storage_class,omitempty"`
+	ETag         string `json:"etag,omitempty"`
+}
+
+var listingHeader = []string{"name", "type", "size", "last_modified", "storage_class", "etag"}
+
+func newListingRecord(obj *S3Object) *ListingRecord {
+	r := &ListingRecord{
+		Name:         obj.Name,
+		StorageClass: obj.StorageClass,
+		ETag:         strings.Trim(obj.ETag, `"`),
+	}
+	switch obj.ObjType {
+	case Bucket:
+		r.Type = "bucket"
+	case Dir:
+		r.Type = "dir"
+	default:
+		r.Type = "object"
+	}
+	if obj.Size != nil {
+		r.Size = *obj.Size
+	}
+	if obj.Date != nil {
+		r.LastModified = obj.Date.UTC().Format(time.RFC3339)
+	}
+	return r
+}
+
+func (r *ListingRecord) values() []string {
+	return []string{r.Name, r.Type, strconv.FormatInt(r.Size, 10), r.LastModified, r.StorageClass, r.ETag}
+}
+
+// WriteListing writes objects as JSON lines, CSV with header or aligned table. Parent entry is skipped.
+func WriteListing(w io.Writer, format OutputFormat, objects []*S3Object) error {
+	var records []*ListingRecord
+	for _, obj := range objects {
+		if obj.ObjType != PreDir {
+			records = append(records, newListingRecord(obj))
+		}
+	}
+
+	switch format {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputCSV:
+		cw := csv.NewWriter(w)
+		cw.Write(listingHeader)
+		for _, r := range records {
+			cw.Write(r.values())
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(listingHeader, "\t")))
+	for _, r := range records {
+		fmt.Fprintln(tw, strings.Join(r.values(), "\t"))
+	}
+	return tw.Flush()
+}
+
+// StatRecord is status of object in output.
+type StatRecord struct {
+	URL                  string            `json:"url"`
+	Size                 int64             `json:"size"`
+	LastModified         string            `json:"last_modified"`
+	ETag                 string            `json:"etag"`
+	ContentType          string            `json:"content_type,omitempty"`
+	ContentEncoding      string            `json:"content_encoding,omitempty"`
+	CacheControl         string            `json:"cache_control,omitempty"`
+	ContentDisposition   string            `json:"content_disposition,omitempty"`
+	StorageClass         string            `json:"storage_class"`
+	ServerSideEncryption string            `json:"server_side_encryption,omitempty"`
+	SSEKMSKeyID          string            `json:"sse_kms_key_id,omitempty"`
+	Metadata             map[string]string `json:"metadata,omitempty"`
+	Tags                 map[string]string `json:"tags,omitempty"`
+}
+
+var statHeader = []string{
+	"url", "size", "last_modified", "etag", "content_type", "content_encoding", "cache_control",
+	"content_disposition", "storage_class", "server_side_encryption", "sse_kms_key_id", "metadata", "tags",
+}
+
+func newStatRecord(stat *ObjectStat) *StatRecord {
+	props := stat.Properties
+	storageClass := props.StorageClass
+	if storageClass == "" {
+		// HEAD omits storage class of STANDARD objects
+		storageClass = "STANDARD"
+	}
+	return &StatRecord{
+		URL:                  S3URL(stat.Bucket, stat.Key),
+		Size:                 stat.Size,
+		LastModified:         stat.LastModified.UTC().Format(time.RFC3339),
+		ETag:                 strings.Trim(stat.ETag, `"`),
+		ContentType:          props.ContentType,
+		ContentEncoding:      props.ContentEncoding,
+		CacheControl:         props.CacheControl,
+		ContentDisposition:   props.ContentDisposition,
+		StorageClass:         storageClass,
+		ServerSideEncryption: props.ServerSideEncryption,
+		SSEKMSKeyID:          props.SSEKMSKeyID,
+		Metadata:             props.Metadata,
+		Tags:                 props.Tags,
+	}
+}
+
+func (r *StatRecord) values() []string {
+	return []string{
+		r.URL, strconv.FormatInt(r.Size, 10), r.LastModified, r.ETag, r.ContentType, r.ContentEncoding, r.CacheControl,
+		r.ContentDisposition, r.StorageClass, r.ServerSideEncryption, r.SSEKMSKeyID, encodeMap(r.Metadata), encodeMap(r.Tags),
+	}
+}
+
+// encodeMap encodes map as query string to fit in a CSV field.
+func encodeMap(m map[string]string) string {
+	values := url.Values{}
+	for k, v := range m {
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
+
+// WriteStats writes status of objects as JSON lines, CSV with header or "Name: value" lines.
+func WriteStats(w io.Writer, format OutputFormat, stats []*ObjectStat) error {
+	switch format {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		for _, stat := range stats {
+			if err := enc.Encode(newStatRecord(stat)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputCSV:
+		cw := csv.NewWriter(w)
+		cw.Write(statHeader)
+		for _, stat := range stats {
+			cw.Write(newStatRecord(stat).values())
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	for i, stat := range stats {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		r := newStatRecord(stat)
+		values := r.values()
+		for j, name := range statHeader[:len(statHeader)-2] {
+			if values[j] != "" {
+				fmt.Fprintf(w, "%-23s %s\n", name+":", values[j])
+			}
+		}
+		writeMap(w, "metadata", r.Metadata)
+		writeMap(w, "tags", r.Tags)
+	}
+	return nil
+}
+
+func writeMap(w io.Writer, name string, m map[string]string) {
+	if len(m) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fmt.Fprintf(w, "%s:\n", name)
+	for _, k := range keys {
+		fmt.Fprintf(w, "  %s: %s\n", k, m[k])
+	}
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWriteListing(t *testing.T) {
+	date := time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC)
+	size := int64(1024)
+	obj := NewS3Object(Object, "logs/app.log", &date, &size)
+	obj.ETag = `"abc"`
+	obj.StorageClass = "STANDARD_IA"
+	objects := []*S3Object{
+		NewS3Object(PreDir, "..", nil, nil),
+		NewS3Object(Dir, "logs/2019/", nil, nil),
+		obj,
+	}
+
+	tests := []struct {
+		format OutputFormat
+		want   string
+	}{
+		{
+			OutputJSON,
+			`{"name":"logs/2019/","type":"dir","size":0}` + "\n" +
+				`{"name":"logs/app.log","type":"object","size":1024,"last_modified":"2019-04-01T12:00:00Z","storage_class":"STANDARD_IA","etag":"abc"}` + "\n",
+		},
+		{
+			OutputCSV,
+			"name,type,size,last_modified,storage_class,etag\n" +
+				"logs/2019/,dir,0,,,\n" +
+				"logs/app.log,object,1024,2019-04-01T12:00:00Z,STANDARD_IA,abc\n",
+		},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := WriteListing(&buf, tt.format, objects); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("WriteListing(%s) =\n%s\nwant\n%s", tt.format, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package model
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const (
+	pagerChunkSize int64 = 256 * 1024
+	pagerMaxChunks       = 64
+	pagerScanSize  int64 = 1024 * 1024
+	// MaxLineLength is length of the longest line the pager reads, longer lines are split.
+	MaxLineLength int64 = 64 * 1024
+	// lineCheckpoint is the interval of line numbers to remember the offset of.
+	lineCheckpoint int64 = 1000
+)
+
+// ObjectReader reads an object by ranged GET on demand, keeping recently read chunks.
+type ObjectReader struct {
+	Bucket string
+	Key    string
+	size   int64
+	etag   string
+	chunks map[int64][]byte
+	order  []int64
+	mutex  sync.Mutex
+}
+
+func NewObjectReader(bucket, key string) (*ObjectReader, error) {
+	r := &ObjectReader{
+		Bucket: bucket,
+		Key:    key,
+		chunks: map[int64][]byte{},
+	}
+	if _, err := r.Refresh(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *ObjectReader) Size() int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.size
+}
+
+// Refresh reloads size of object, dropping read chunks when the object was replaced.
+func (r *ObjectReader) Refresh() (bool, error) {
+	head, err := Head(r.Bucket, r.Key)
+	if err != nil {
+		return false, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	etag := aws.StringValue(head.ETag)
+	if etag == r.etag {
+		return false, nil
+	}
+	r.etag = etag
+	r.size = aws.Int64Value(head.ContentLength)
+	r.chunks = map[int64][]byte{}
+	r.order = nil
+	return true, nil
+}
+
+func (r *ObjectReader) ReadAt(p []byte, off int64) (int, error) {
+	size := r.Size()
+	n := 0
+	for n < len(p) && off+int64(n) < size {
+		pos := off + int64(n)
+		chunk, err := r.chunk(pos / pagerChunkSize)
+		if err != nil {
+			return n, err
+		}
+		start := pos % pagerChunkSize
+		if start >= int64(len(chunk)) {
+			break
+		}
+		n += copy(p[n:], chunk[start:])
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *ObjectReader) chunk(index int64) ([]byte, error) {
+	r.mutex.Lock()
+	chunk, ok := r.chunks[index]
+	r.mutex.Unlock()
+	if ok {
+		return chunk, nil
+	}
+
+	_, chunk, err := GetRange(r.Bucket, r.Key, index*pagerChunkSize, pagerChunkSize)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.chunks[index] = chunk
+	r.order = append(r.order, index)
+	if len(r.order) > pagerMaxChunks {
+		delete(r.chunks, r.order[0])
+		r.order = r.order[1:]
+	}
+	return chunk, nil
+}
+
+type PagerSource interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// Pager navigates lines of a large file by byte offset without reading it whole.
+type Pager struct {
+	src PagerSource
+	// checkpoints[i] is offset of line number i*lineCheckpoint+1
+	checkpoints []int64
+	mutex       sync.Mutex
+}
+
+func NewPager(src PagerSource) *Pager {
+	return &Pager{
+		src:         src,
+		checkpoints: []int64{0},
+	}
+}
+
+func (p *Pager) Size() int64 {
+	return p.src.Size()
+}
+
+// Reset forgets line offsets, for when the source has been replaced.
+func (p *Pager) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.checkpoints = []int64{0}
+}
+
+func (p *Pager) read(off, length int64) ([]byte, error) {
+	if off < 0 {
+		length += off
+		off = 0
+	}
+	if size := p.src.Size(); off+length > size {
+		length = size - off
+	}
+	if length <= 0 {
+		return []byte{}, nil
+	}
+	b := make([]byte, length)
+	n, err := p.src.ReadAt(b, off)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return b[:n], nil
+}
+
+// ReadLine returns line starting at off and offset of the next line.
+func (p *Pager) ReadLine(off int64) (string, int64, error) {
+	b, err := p.read(off, MaxLineLength)
+	if err != nil {
+		return "", off, err
+	}
+	i := bytes.IndexByte(b, '\n')
+	if i < 0 {
+		return string(b), off + int64(len(b)), nil
+	}
+	return string(bytes.TrimSuffix(b[:i], []byte("\r"))), off + int64(i) + 1, nil
+}
+
+// LineStart returns offset of the start of line containing off.
+func (p *Pager) LineStart(off int64) (int64, error) {
+	if off <= 0 {
+		return 0, nil
+	}
+	from := off - MaxLineLength
+	b, err := p.read(from, off-from)
+	if err != nil {
+		return off, err
+	}
+	i := bytes.LastIndexByte(b, '\n')
+	if i < 0 {
+		if from < 0 {
+			return 0, nil
+		}
+		return from, nil
+	}
+	return off - int64(len(b)) + int64(i) + 1, nil
+}
+
+// PrevLine returns offset of the start of line before the line starting at off.
+func (p *Pager) PrevLine(off int64) (int64, error) {
+	if off <= 0 {
+		return 0, nil
+	}
+	return p.LineStart(off - 1)
+}
+
+// LastLine returns offset of the start of the last line.
+func (p *Pager) LastLine() (int64, error) {
+	size := p.src.Size()
+	if size == 0 {
+		return 0, nil
+	}
+	b, err := p.read(size-1, 1)
+	if err != nil {
+		return 0, err
+	}
+	if len(b) > 0 && b[0] == '\n' {
+		return p.LineStart(size - 1)
+	}
+	return p.LineStart(size)
+}
+
+// Search finds query from off to the end, or to the beginning when backward.
+// It returns offset of match, or -1 when not found.
+func (p *Pager) Search(off int64, query string, backward bool) (int64, error) {
+	q := []byte(query)
+	overlap := int64(len(q) - 1)
+	size := p.src.Size()
+	if len(q) == 0 {
+		return -1, nil
+	}
+
+	if !backward {
+		for pos := off; pos < size; pos += pagerScanSize {
+			b, err := p.read(pos, pagerScanSize+overlap)
+			if err != nil {
+				return -1, err
+			}
+			if i := bytes.Index(b, q); i >= 0 {
+				return pos + int64(i), nil
+			}
+		}
+		return -1, nil
+	}
+
+	for end := off; end > 0; end -= pagerScanSize {
+		b, err := p.read(end-pagerScanSize, pagerScanSize+overlap)
+		if err != nil {
+			return -1, err
+		}
+		if i := bytes.LastIndex(b, q); i >= 0 {
+			start := end - pagerScanSize
+			if start < 0 {
+				start = 0
+			}
+			if match := start + int64(i); match < off {
+				return match, nil
+			}
+		}
+	}
+	return -1, nil
+}
+
+// LineOffset returns offset of line number n counted from 1, scanning from
+// the nearest line already seen. Lines over the end return the last line.
+func (p *Pager) LineOffset(n int64) (int64, error) {
+	if n < 1 {
+		n = 1
+	}
+	p.mutex.Lock()
+	index := (n - 1) / lineCheckpoint
+	if index >= int64(len(p.checkpoints)) {
+		index = int64(len(p.checkpoints)) - 1
+	}
+	pos := p.checkpoints[index]
+	line := index*lineCheckpoint + 1
+	p.mutex.Unlock()
+
+	size := p.src.Size()
+	for line < n && pos < size {
+		b, err := p.read(pos, pagerScanSize)
+		if err != nil {
+			return 0, err
+		}
+		for i, c := range b {
+			if c != '\n' {
+				continue
+			}
+			line++
+			if (line-1)%lineCheckpoint == 0 {
+				p.addCheckpoint(line, pos+int64(i)+1)
+			}
+			if line == n && pos+int64(i)+1 < size {
+				return pos + int64(i) + 1, nil
+			}
+		}
+		pos += int64(len(b))
+	}
+	if line < n || pos >= size {
+		return p.LastLine()
+	}
+	return pos, nil
+}
+
+func (p *Pager) addCheckpoint(line, off int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if index := (line - 1) / lineCheckpoint; index == int64(len(p.checkpoints)) {
+		p.checkpoints = append(p.checkpoints, off)
+	}
+}
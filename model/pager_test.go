@@ -0,0 +1,112 @@
+package model
+
+import (
+	"bytes"
+	"testing"
+)
+
+type bytesSource struct {
+	*bytes.Reader
+}
+
+func newPager(text string) *Pager {
+	return NewPager(&bytesSource{bytes.NewReader([]byte(text))})
+}
+
+func TestPagerReadLine(t *testing.T) {
+	pager := newPager("first\r\nsecond\nlast")
+	want := []string{"first", "second", "last"}
+	off := int64(0)
+	for _, w := range want {
+		line, next, err := pager.ReadLine(off)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != w {
+			t.Errorf("want %q, but %q", w, line)
+		}
+		off = next
+	}
+	if off != pager.Size() {
+		t.Errorf("want end offset %d, but %d", pager.Size(), off)
+	}
+}
+
+func TestPagerPrevLine(t *testing.T) {
+	pager := newPager("a\nbb\nccc\n")
+	tests := []struct {
+		off  int64
+		want int64
+	}{
+		{0, 0},
+		{2, 0},
+		{5, 2},
+		{9, 5},
+	}
+	for _, tt := range tests {
+		got, err := pager.PrevLine(tt.off)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("PrevLine(%d) want %d, but %d", tt.off, tt.want, got)
+		}
+	}
+
+	last, _ := pager.LastLine()
+	if last != 5 {
+		t.Errorf("LastLine() want 5, but %d", last)
+	}
+}
+
+func TestPagerSearch(t *testing.T) {
+	pager := newPager("error one\ninfo\nerror two\n")
+	tests := []struct {
+		off      int64
+		backward bool
+		want     int64
+	}{
+		{0, false, 0},
+		{1, false, 15},
+		{16, false, -1},
+		{15, true, 0},
+		{26, true, 15},
+	}
+	for _, tt := range tests {
+		got, err := pager.Search(tt.off, "error", tt.backward)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Search(%d, backward=%v) want %d, but %d", tt.off, tt.backward, tt.want, got)
+		}
+	}
+}
+
+func TestPagerLineOffset(t *testing.T) {
+	var buf bytes.Buffer
+	for i := 0; i < 2500; i++ {
+		buf.WriteString("line\n")
+	}
+	pager := NewPager(&bytesSource{bytes.NewReader(buf.Bytes())})
+	tests := []struct {
+		line int64
+		want int64
+	}{
+		{1, 0},
+		{2, 5},
+		{1001, 5000},
+		{2500, 12495},
+		{9999, 12495},
+		{1500, 7495},
+	}
+	for _, tt := range tests {
+		got, err := pager.LineOffset(tt.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("LineOffset(%d) want %d, but %d", tt.line, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+// PrefetchJob is listing of a child prefix of Node to prefetch.
+type PrefetchJob struct {
+	Lister  Lister
+	Bucket  string
+	Node    *Node
+	Key     string
+	Objects []*S3Object
+	Err     error
+}
+
+// prefix returns prefix to list, bucket root for child of bucket list.
+func (j *PrefetchJob) prefix() string {
+	if j.Node.IsRoot() {
+		return ""
+	}
+	return j.Key
+}
+
+// Prefetcher lists prefixes in background by bounded workers at limited rate.
+// It stops prefetching once S3 throttles requests.
+type Prefetcher struct {
+	jobs      chan *PrefetchJob
+	tick      <-chan time.Time
+	done      func(*PrefetchJob)
+	throttled int32
+}
+
+// NewPrefetcher starts workers which list at most one prefix per interval in total.
+// Done is called by workers with the listed job.
+func NewPrefetcher(workers int, interval time.Duration, done func(*PrefetchJob)) *Prefetcher {
+	f := &Prefetcher{
+		jobs: make(chan *PrefetchJob, workers*4),
+		tick: time.Tick(interval),
+		done: done,
+	}
+	for i := 0; i < workers; i++ {
+		go f.work()
+	}
+	return f
+}
+
+// Request queues job. It returns false when the queue is full or prefetch is disabled.
+func (f *Prefetcher) Request(job *PrefetchJob) bool {
+	if f.Disabled() {
+		return false
+	}
+	select {
+	case f.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// Disabled reports whether prefetch has been stopped by throttling.
+func (f *Prefetcher) Disabled() bool {
+	return atomic.LoadInt32(&f.throttled) != 0
+}
+
+func (f *Prefetcher) work() {
+	for job := range f.jobs {
+		<-f.tick
+		if f.Disabled() {
+			job.Err = errPrefetchDisabled
+			f.done(job)
+			continue
+		}
+		job.Objects, job.Err = FetchList(job.Lister, job.Bucket, job.prefix())
+		if IsThrottled(job.Err) {
+			log.Printf("prefetch disabled by throttling, %v", job.Err)
+			atomic.StoreInt32(&f.throttled, 1)
+		}
+		f.done(job)
+	}
+}
+
+var errPrefetchDisabled = errors.New("prefetch disabled")
+
+// IsThrottled reports whether err is S3 asking to slow down requests.
+func IsThrottled(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+	switch aerr.Code() {
+	case "SlowDown", "Throttling", "ThrottlingException", "RequestLimitExceeded", "TooManyRequestsException":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+type throttleError struct{}
+
+func (e throttleError) Error() string   { return "SlowDown: Please reduce your request rate." }
+func (e throttleError) Code() string    { return "SlowDown" }
+func (e throttleError) Message() string { return "Please reduce your request rate." }
+func (e throttleError) OrigErr() error  { return nil }
+
+// prefetchLister lists objects of prefix, failing for "slow/" by throttling.
+type prefetchLister struct{}
+
+func (l *prefetchLister) ListBuckets() []*S3Object { return nil }
+func (l *prefetchLister) ListObjects(bucket, prefix string) []*S3Object {
+	return []*S3Object{NewS3Object(Object, prefix+"a", nil, nil)}
+}
+func (l *prefetchLister) IsLocal() bool                      { return false }
+func (l *prefetchLister) FetchBuckets() ([]*S3Object, error) { return nil, nil }
+func (l *prefetchLister) FetchObjects(bucket, prefix string) ([]*S3Object, error) {
+	if prefix == "slow/" {
+		return nil, throttleError{}
+	}
+	return l.ListObjects(bucket, prefix), nil
+}
+
+func TestPrefetcher(t *testing.T) {
+	done := make(chan *PrefetchJob)
+	f := NewPrefetcher(2, time.Millisecond, func(job *PrefetchJob) {
+		done <- job
+	})
+	node := NewNode("bucket", NewNode("", nil, nil), nil)
+	lister := &prefetchLister{}
+
+	if !f.Request(&PrefetchJob{Lister: lister, Bucket: "bucket", Node: node, Key: "logs/"}) {
+		t.Fatal("request refused")
+	}
+	job := <-done
+	if job.Err != nil || len(job.Objects) != 1 || job.Objects[0].Name != "logs/a" {
+		t.Fatalf("prefetched %v, %v", job.Objects, job.Err)
+	}
+
+	f.Request(&PrefetchJob{Lister: lister, Bucket: "bucket", Node: node, Key: "slow/"})
+	job = <-done
+	if !IsThrottled(job.Err) {
+		t.Fatalf("want throttled error, %v", job.Err)
+	}
+	if !f.Disabled() {
+		t.Error("want disabled by throttling")
+	}
+	if f.Request(&PrefetchJob{Lister: lister, Bucket: "bucket", Node: node, Key: "logs/"}) {
+		t.Error("want request refused after throttling")
+	}
+}
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const (
+	PreviewSize int64 = 16 * 1024
+	ViewerSize  int64 = 1024 * 1024
+)
+
+type Preview struct {
+	Key         string
+	ObjType     S3ObjectType
+	ETag        string
+	ContentType string
+	Encoding    string
+	Format      Format
+	Body        []byte
+	Binary      bool
+	Truncated   bool
+	Fetched     int64
+	Size        int64
+	Dirs        int
+	Objects     int
+}
+
+var (
+	previewCache = map[string]*Preview{}
+	previewMutex sync.Mutex
+)
+
+// LoadPreview fetches the first size bytes of object, or a summary of bucket and directory.
+func LoadPreview(bucket string, obj *S3Object, size int64) (*Preview, error) {
+	switch obj.ObjType {
+	case Bucket:
+		return loadDirPreview(obj.Name, "", obj)
+	case Dir:
+		return loadDirPreview(bucket, obj.Name, obj)
+	case Object:
+		return loadObjectPreview(bucket, obj, size)
+	}
+	return &Preview{Key: obj.Name, ObjType: obj.ObjType}, nil
+}
+
+func loadDirPreview(bucket, prefix string, obj *S3Object) (*Preview, error) {
+	children, err := FetchObjects(bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+	preview := &Preview{
+		Key:     obj.Name,
+		ObjType: obj.ObjType,
+	}
+	for _, child := range children {
+		switch child.ObjType {
+		case Dir:
+			preview.Dirs++
+		case Object:
+			preview.Objects++
+			preview.Size += aws.Int64Value(child.Size)
+		}
+	}
+	return preview, nil
+}
+
+func loadObjectPreview(bucket string, obj *S3Object, size int64) (*Preview, error) {
+	cacheKey := bucket + "/" + obj.Name
+	previewMutex.Lock()
+	cached, ok := previewCache[cacheKey]
+	previewMutex.Unlock()
+	if ok && obj.ETag != "" && cached.ETag == obj.ETag && (!cached.Truncated || cached.Fetched >= size) {
+		return cached, nil
+	}
+
+	preview := &Preview{
+		Key:     obj.Name,
+		ObjType: obj.ObjType,
+		ETag:    obj.ETag,
+		Size:    aws.Int64Value(obj.Size),
+	}
+	if preview.Size > 0 {
+		result, b, err := GetRange(bucket, obj.Name, 0, size)
+		if err != nil {
+			return nil, err
+		}
+		preview.ETag = aws.StringValue(result.ETag)
+		preview.ContentType = aws.StringValue(result.ContentType)
+		preview.Encoding = DetectEncoding(obj.Name, aws.StringValue(result.ContentEncoding), b)
+		preview.Body = decodePreview(preview.Encoding, b, size*4)
+		preview.Fetched = int64(len(b))
+		preview.Truncated = preview.Size > preview.Fetched
+		preview.Binary = IsBinary(preview.Body)
+	}
+	preview.Format = DetectFormat(TrimEncodingSuffix(obj.Name), preview.ContentType)
+
+	previewMutex.Lock()
+	previewCache[cacheKey] = preview
+	previewMutex.Unlock()
+	return preview, nil
+}
+
+// decodePreview decompresses as much of the partial body as possible.
+func decodePreview(encoding string, b []byte, limit int64) []byte {
+	if encoding == "" {
+		return b
+	}
+	r, err := NewDecoder(encoding, bytes.NewReader(b))
+	if err != nil {
+		return b
+	}
+	defer r.Close()
+	out, _ := ioutil.ReadAll(io.LimitReader(r, limit))
+	return out
+}
+
+// IsBinary reports whether the head of a file looks like binary data.
+func IsBinary(b []byte) bool {
+	if bytes.IndexByte(b, 0) >= 0 {
+		return true
+	}
+	// ignore rune cut off at the end of ranged read
+	for i := len(b) - 1; i >= 0 && i > len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				b = b[:i]
+			}
+			break
+		}
+	}
+	return !utf8.Valid(b)
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		body []byte
+		want bool
+	}{
+		{[]byte("plain text\n"), false},
+		{[]byte("cut off rune \xe3\x81"), false},
+		{[]byte("null \x00 byte"), true},
+		{[]byte{0xff, 0xfe, 0x41, 0x42, 0x43, 0x44}, true},
+		{[]byte("\xffabc"), true},
+		{[]byte("abc\xff"), true},
+	}
+	for _, tt := range tests {
+		got := IsBinary(tt.body)
+		if got != tt.want {
+			t.Errorf("IsBinary(%q) want %v, but %v", tt.body, tt.want, got)
+		}
+	}
+}
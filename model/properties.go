@@ -0,0 +1,155 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// ObjectProperties is metadata, tags and storage settings of object
+// which have to be given again when the object is put.
+type ObjectProperties struct {
+	ContentType             string
+	CacheControl            string
+	ContentDisposition      string
+	ContentEncoding         string
+	ContentLanguage         string
+	Expires                 string
+	Metadata                map[string]string
+	Tags                    map[string]string
+	StorageClass            string
+	ServerSideEncryption    string
+	SSEKMSKeyID             string
+	WebsiteRedirectLocation string
+}
+
+func newObjectProperties(result *s3.GetObjectOutput) *ObjectProperties {
+	return &ObjectProperties{
+		ContentType:             aws.StringValue(result.ContentType),
+		CacheControl:            aws.StringValue(result.CacheControl),
+		ContentDisposition:      aws.StringValue(result.ContentDisposition),
+		ContentEncoding:         aws.StringValue(result.ContentEncoding),
+		ContentLanguage:         aws.StringValue(result.ContentLanguage),
+		Expires:                 aws.StringValue(result.Expires),
+		Metadata:                aws.StringValueMap(result.Metadata),
+		Tags:                    map[string]string{},
+		StorageClass:            aws.StringValue(result.StorageClass),
+		ServerSideEncryption:    aws.StringValue(result.ServerSideEncryption),
+		SSEKMSKeyID:             aws.StringValue(result.SSEKMSKeyId),
+		WebsiteRedirectLocation: aws.StringValue(result.WebsiteRedirectLocation),
+	}
+}
+
+func GetTags(bucket, key string) (map[string]string, error) {
+	client := getS3Client()
+
+	ctx := context.Background()
+	ctx, cancelFn := context.WithTimeout(ctx, RequestTimeout)
+	defer cancelFn()
+
+	result, err := client.GetObjectTaggingWithContext(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed get object tagging, %v", err)
+	}
+	tags := map[string]string{}
+	for _, tag := range result.TagSet {
+		tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	return tags, nil
+}
+
+// tagging encodes tags to the query string form of x-amz-tagging header.
+func tagging(tags map[string]string) *string {
+	if len(tags) == 0 {
+		return nil
+	}
+	values := url.Values{}
+	for k, v := range tags {
+		values.Set(k, v)
+	}
+	return aws.String(values.Encode())
+}
+
+func tagSet(tags map[string]string) []*s3.Tag {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	set := make([]*s3.Tag, 0, len(tags))
+	for _, k := range keys {
+		set = append(set, &s3.Tag{Key: aws.String(k), Value: aws.String(tags[k])})
+	}
+	return set
+}
+
+func optionalString(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return aws.String(v)
+}
+
+// applyTo sets properties to put object input.
+func (props *ObjectProperties) applyTo(input *s3.PutObjectInput) {
+	if props == nil {
+		return
+	}
+	input.ContentType = optionalString(props.ContentType)
+	input.CacheControl = optionalString(props.CacheControl)
+	input.ContentDisposition = optionalString(props.ContentDisposition)
+	input.ContentEncoding = optionalString(props.ContentEncoding)
+	input.ContentLanguage = optionalString(props.ContentLanguage)
+	if expires, err := http.ParseTime(props.Expires); err == nil {
+		input.Expires = aws.Time(expires)
+	}
+	if len(props.Metadata) > 0 {
+		input.Metadata = aws.StringMap(props.Metadata)
+	}
+	input.Tagging = tagging(props.Tags)
+	input.StorageClass = optionalString(props.StorageClass)
+	input.ServerSideEncryption = optionalString(props.ServerSideEncryption)
+	if props.ServerSideEncryption == s3.ServerSideEncryptionAwsKms {
+		input.SSEKMSKeyId = optionalString(props.SSEKMSKeyID)
+	}
+	input.WebsiteRedirectLocation = optionalString(props.WebsiteRedirectLocation)
+}
+
+// ObjectStat is status of object with its properties.
+type ObjectStat struct {
+	Bucket       string
+	Key          string
+	Size         int64
+	LastModified time.Time
+	ETag         string
+	Properties   *ObjectProperties
+}
+
+// Stat returns status, properties and tags of object.
+func Stat(bucket, key string) (*ObjectStat, error) {
+	head, err := Head(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	props, err := headProperties(bucket, key, head)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectStat{
+		Bucket:       bucket,
+		Key:          key,
+		Size:         aws.Int64Value(head.ContentLength),
+		LastModified: aws.TimeValue(head.LastModified),
+		ETag:         aws.StringValue(head.ETag),
+		Properties:   props,
+	}, nil
+}
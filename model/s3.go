@@ -19,6 +19,9 @@ type S3Object struct {
 	Name    string
 	Date    *time.Time
 	Size    *int64
+	ETag    string
+	// StorageClass is empty for directory and local file.
+	StorageClass string
 }
 
 func NewS3Object(objType S3ObjectType, name string, date *time.Time, size *int64) *S3Object {
@@ -36,6 +39,10 @@ type Node struct {
 	children map[string]*Node
 	Objects  []*S3Object
 	Position int
+	// ListedAt is when Objects were listed, zero after invalidated.
+	ListedAt time.Time
+	// CachedAt is when Objects served from disk cache were listed, zero for listing of S3.
+	CachedAt time.Time
 }
 
 func NewNode(key string, parent *Node, objects []*S3Object) *Node {
@@ -44,6 +51,7 @@ func NewNode(key string, parent *Node, objects []*S3Object) *Node {
 		Parent:   parent,
 		Objects:  objects,
 		children: map[string]*Node{},
+		ListedAt: time.Now(),
 	}
 	if len(objects) > 1 {
 		node.Position = 1
@@ -86,6 +94,14 @@ func (n *Node) GetType() S3ListType {
 	return ObjectList
 }
 
+// Prefix returns key prefix of objects listed by node, bucket root has empty prefix.
+func (n *Node) Prefix() string {
+	if n.IsRoot() || n.IsBucketRoot() {
+		return ""
+	}
+	return n.Key
+}
+
 func (n *Node) IsExistChildren(key string) bool {
 	_, ok := n.children[key]
 	return ok
@@ -99,6 +115,31 @@ func (n *Node) AddChild(key string, node *Node) {
 	n.children[key] = node
 }
 
+// AddObject inserts obj into listing in the order of listing, directories before objects,
+// replacing the entry of the same name. It returns position of the entry.
+func (n *Node) AddObject(obj *S3Object) int {
+	for i, o := range n.Objects {
+		if o.Name == obj.Name {
+			n.Objects[i] = obj
+			return i
+		}
+	}
+	pos := len(n.Objects)
+	for i, o := range n.Objects {
+		if o.ObjType == PreDir {
+			continue
+		}
+		if o.ObjType > obj.ObjType || (o.ObjType == obj.ObjType && o.Name > obj.Name) {
+			pos = i
+			break
+		}
+	}
+	n.Objects = append(n.Objects, nil)
+	copy(n.Objects[pos+1:], n.Objects[pos:])
+	n.Objects[pos] = obj
+	return pos
+}
+
 func Filename(path string) string {
 	sp := strings.Split(path, "/")
 	return sp[len(sp)-1]
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// searchBatchSize is number of local files walked between calls of found.
+const searchBatchSize = 1000
+
+var errSearchStopped = errors.New("search stopped")
+
+// Matcher tells whether key relative to the searched prefix matches.
+type Matcher func(rel string) bool
+
+// NewMatcher returns matcher of pattern. Pattern enclosed by slashes is regular expression,
+// pattern with *, ? or [ is glob, matched to base name unless it has slash, and the others are
+// substring ignoring case.
+func NewMatcher(pattern string) (Matcher, error) {
+	if pattern == "" {
+		return nil, errors.New("empty pattern")
+	}
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression, %v", err)
+		}
+		return re.MatchString, nil
+	}
+	if strings.ContainsAny(pattern, "*?[") {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob, %v", err)
+		}
+		if strings.Contains(pattern, "/") {
+			return func(rel string) bool {
+				ok, _ := path.Match(pattern, rel)
+				return ok
+			}, nil
+		}
+		return func(rel string) bool {
+			ok, _ := path.Match(pattern, path.Base(rel))
+			return ok
+		}, nil
+	}
+	lower := strings.ToLower(pattern)
+	return func(rel string) bool {
+		return strings.Contains(strings.ToLower(rel), lower)
+	}, nil
+}
+
+// SearchObjects lists all objects under prefix of bucket on S3 of profile without delimiter.
+// Found is called with matched objects and number of objects listed so far after each page,
+// and stops the search by returning false.
+func SearchObjects(profile, bucket, prefix string, match Matcher, found func([]*S3Object, int64) bool) error {
+	var scanned int64
+	return walkObjects(getS3ClientFor(profile), bucket, prefix, func(page []*S3Object) bool {
+		var matches []*S3Object
+		for _, obj := range page {
+			if match(strings.TrimPrefix(obj.Name, prefix)) {
+				matches = append(matches, obj)
+			}
+		}
+		scanned += int64(len(page))
+		return found(matches, scanned)
+	})
+}
+
+// SearchLocalFiles walks files under key of local filesystem in the same way as SearchObjects.
+func SearchLocalFiles(bucket, key string, match Matcher, found func([]*S3Object, int64) bool) error {
+	var scanned int64
+	var matches []*S3Object
+	base := LocalPath(bucket, key)
+	err := filepath.Walk(base, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			// unreadable directories are skipped to search the others
+			if info != nil && info.IsDir() && p != base {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(base, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if match(rel) {
+			modTime, size := info.ModTime(), info.Size()
+			matches = append(matches, NewS3Object(Object, key+rel, &modTime, &size))
+		}
+		scanned++
+		if scanned%searchBatchSize == 0 {
+			if !found(matches, scanned) {
+				return errSearchStopped
+			}
+			matches = nil
+		}
+		return nil
+	})
+	if err == errSearchStopped {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed search files, %v", err)
+	}
+	found(matches, scanned)
+	return nil
+}
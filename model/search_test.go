@@ -0,0 +1,80 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNewMatcher(t *testing.T) {
+	tests := []struct {
+		pattern string
+		rel     string
+		want    bool
+	}{
+		{"Report", "2020/01/01/report.csv", true},
+		{"report", "2020/01/01/summary.csv", false},
+		{"*.csv", "2020/01/01/report.csv", true},
+		{"*.csv", "2020/01/01/report.json", false},
+		{"2020/*/01/*.csv", "2020/01/01/report.csv", true},
+		{"2020/*.csv", "2020/01/01/report.csv", false},
+		{"/^2020/0[1-3]/.*\\.csv$/", "2020/02/11/report.csv", true},
+		{"/^2020/0[1-3]/.*\\.csv$/", "2020/04/11/report.csv", false},
+	}
+	for _, tt := range tests {
+		match, err := NewMatcher(tt.pattern)
+		if err != nil {
+			t.Fatalf("NewMatcher(%q): %v", tt.pattern, err)
+		}
+		if got := match(tt.rel); got != tt.want {
+			t.Errorf("match %q by %q = %v, want %v", tt.rel, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestNewMatcherInvalid(t *testing.T) {
+	for _, pattern := range []string{"", "[", "/(/"} {
+		if _, err := NewMatcher(pattern); err == nil {
+			t.Errorf("NewMatcher(%q) is not error", pattern)
+		}
+	}
+}
+
+func TestSearchLocalFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s3tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"a/x.log", "a/b/y.log", "a/b/z.txt", "c.log"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bucket, key := LocalKey(dir)
+	match, _ := NewMatcher("*.log")
+	var got []string
+	var scanned int64
+	err = SearchLocalFiles(bucket, key+"a/", match, func(matches []*S3Object, n int64) bool {
+		for _, obj := range matches {
+			got = append(got, obj.Name)
+		}
+		scanned = n
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{key + "a/b/y.log", key + "a/x.log"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("matches = %v, want %v", got, want)
+	}
+	if scanned != 3 {
+		t.Errorf("scanned = %d, want 3", scanned)
+	}
+}
@@ -0,0 +1,244 @@
+package model
+
+import (
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+// Lister lists buckets and objects of a storage shown by Node.
+type Lister interface {
+	ListBuckets() []*S3Object
+	ListObjects(bucket, prefix string) []*S3Object
+	IsLocal() bool
+}
+
+// S3Lister lists S3 buckets of a profile.
+type S3Lister struct {
+	Profile string
+}
+
+func NewS3Lister(profile string) *S3Lister {
+	return &S3Lister{Profile: profile}
+}
+
+func (l *S3Lister) ListBuckets() []*S3Object {
+	return listBuckets(getS3ClientFor(l.Profile))
+}
+
+func (l *S3Lister) ListObjects(bucket, prefix string) []*S3Object {
+	return listObjects(getS3ClientFor(l.Profile), bucket, prefix)
+}
+
+func (l *S3Lister) IsLocal() bool {
+	return false
+}
+
+// FetchBuckets lists buckets, returning error instead of exiting.
+func (l *S3Lister) FetchBuckets() ([]*S3Object, error) {
+	return fetchBuckets(getS3ClientFor(l.Profile))
+}
+
+// FetchObjects lists objects in the form of ListObjects, returning error instead of exiting.
+func (l *S3Lister) FetchObjects(bucket, prefix string) ([]*S3Object, error) {
+	objects, err := fetchObjects(getS3ClientFor(l.Profile), bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return append([]*S3Object{NewS3Object(PreDir, "..", nil, nil)}, objects...), nil
+}
+
+// fetcher is lister which can fail listing without exiting, e.g. by lack of permission.
+type fetcher interface {
+	FetchBuckets() ([]*S3Object, error)
+	FetchObjects(bucket, prefix string) ([]*S3Object, error)
+}
+
+// FetchList lists prefix by lister, returning error instead of exiting when lister supports it.
+func FetchList(lister Lister, bucket, prefix string) ([]*S3Object, error) {
+	if f, ok := lister.(fetcher); ok {
+		return f.FetchObjects(bucket, prefix)
+	}
+	return lister.ListObjects(bucket, prefix), nil
+}
+
+// LocalRoot is the only bucket of local filesystem, keys are paths relative to it.
+const LocalRoot = "/"
+
+// LocalLister lists local filesystem in the same form as S3,
+// directories as Dir with trailing slash and files as Object.
+type LocalLister struct{}
+
+func NewLocalLister() *LocalLister {
+	return &LocalLister{}
+}
+
+func (l *LocalLister) ListBuckets() []*S3Object {
+	return []*S3Object{NewS3Object(Bucket, LocalRoot, nil, nil)}
+}
+
+func (l *LocalLister) ListObjects(bucket, prefix string) []*S3Object {
+	objects := []*S3Object{NewS3Object(PreDir, "..", nil, nil)}
+
+	infos, err := ioutil.ReadDir(filepath.Join(bucket, filepath.FromSlash(prefix)))
+	if err != nil {
+		log.Printf("failed read dir, %v", err)
+		return objects
+	}
+	for _, info := range infos {
+		if info.IsDir() {
+			objects = append(objects, NewS3Object(Dir, prefix+info.Name()+"/", nil, nil))
+		}
+	}
+	for _, info := range infos {
+		if !info.IsDir() {
+			modTime, size := info.ModTime(), info.Size()
+			objects = append(objects, NewS3Object(Object, prefix+info.Name(), &modTime, &size))
+		}
+	}
+	return objects
+}
+
+func (l *LocalLister) IsLocal() bool {
+	return true
+}
+
+// LocalKey converts local directory path to bucket and key of LocalLister.
+func LocalKey(dir string) (string, string) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	key := strings.TrimPrefix(filepath.ToSlash(abs), "/")
+	if key != "" {
+		key += "/"
+	}
+	return LocalRoot, key
+}
+
+// LocalPath converts bucket and key of LocalLister to local filesystem path.
+func LocalPath(bucket, key string) string {
+	return filepath.Join(bucket, filepath.FromSlash(key))
+}
+
+// NewNodeTree builds nodes from the bucket list to key, so that parents can be visited.
+// Key is a prefix, a prefix without trailing slash or an object. It returns node of the prefix,
+// with cursor on the object. Parents which cannot be listed by lack of permission only show
+// the way to the prefix.
+func NewNodeTree(lister Lister, bucket, key string) *Node {
+	if bucket == "" {
+		return NewNode("", nil, lister.ListBuckets())
+	}
+	root := NewNode("", nil, listParent(lister, bucket, "", true))
+	return root.Open(lister, bucket, key)
+}
+
+// Open returns node of key in bucket under root node n, reusing visited nodes and listing the others.
+// Key is handled as NewNodeTree does.
+func (n *Node) Open(lister Lister, bucket, key string) *Node {
+	if bucket == "" {
+		return n
+	}
+	prefix := key[:strings.LastIndex(key, "/")+1]
+	keys := []string{bucket}
+	dir := ""
+	for _, name := range strings.SplitAfter(prefix, "/") {
+		if name != "" {
+			dir += name
+			keys = append(keys, dir)
+		}
+	}
+
+	node := n
+	for i, k := range keys {
+		objType, listKey := Dir, k
+		if i == 0 {
+			objType, listKey = Bucket, ""
+		}
+		if child := node.GetChild(k); child != nil {
+			node.pointTo(k, objType)
+			node = child
+			continue
+		}
+		var objects []*S3Object
+		if i == len(keys)-1 {
+			objects = lister.ListObjects(bucket, listKey)
+		} else {
+			objects = listParent(lister, bucket, listKey, false)
+		}
+		node = node.appendChild(k, objType, objects)
+	}
+
+	if key == prefix {
+		return node
+	}
+	for i, obj := range node.Objects {
+		if obj.ObjType == Dir && obj.Name == key+"/" {
+			if child := node.GetChild(obj.Name); child != nil {
+				node.Position = i
+				return child
+			}
+			return node.appendChild(obj.Name, Dir, lister.ListObjects(bucket, obj.Name))
+		}
+		if obj.Name == key {
+			node.Position = i
+		}
+	}
+	return node
+}
+
+// Root returns root node of the tree.
+func (n *Node) Root() *Node {
+	for n.Parent != nil {
+		n = n.Parent
+	}
+	return n
+}
+
+// listParent lists bucket list or prefix on the way to the opening node.
+func listParent(lister Lister, bucket, prefix string, root bool) []*S3Object {
+	f, ok := lister.(fetcher)
+	if !ok {
+		if root {
+			return lister.ListBuckets()
+		}
+		return lister.ListObjects(bucket, prefix)
+	}
+
+	var objects []*S3Object
+	var err error
+	if root {
+		objects, err = f.FetchBuckets()
+	} else {
+		objects, err = f.FetchObjects(bucket, prefix)
+	}
+	if err != nil {
+		log.Printf("failed list parent of opening node, %v", err)
+		if root {
+			return []*S3Object{}
+		}
+		return []*S3Object{NewS3Object(PreDir, "..", nil, nil)}
+	}
+	return objects
+}
+
+// appendChild adds child node of key, pointing the cursor of n to the key.
+func (n *Node) appendChild(key string, objType S3ObjectType, objects []*S3Object) *Node {
+	child := NewNode(key, n, objects)
+	n.AddChild(key, child)
+	n.pointTo(key, objType)
+	return child
+}
+
+// pointTo moves cursor to entry of key. Entry is added when n lacks it,
+// e.g. bucket not owned by the account.
+func (n *Node) pointTo(key string, objType S3ObjectType) {
+	for i, obj := range n.Objects {
+		if obj.Name == key {
+			n.Position = i
+			return
+		}
+	}
+	n.Position = n.AddObject(NewS3Object(objType, key, nil, nil))
+}
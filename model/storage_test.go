@@ -0,0 +1,136 @@
+package model
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalListerListObjects(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s3tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "file.txt"), []byte("hello"), 0644)
+
+	bucket, key := LocalKey(dir)
+	objects := NewLocalLister().ListObjects(bucket, key)
+	want := []struct {
+		objType S3ObjectType
+		name    string
+	}{
+		{PreDir, ".."},
+		{Dir, key + "sub/"},
+		{Object, key + "file.txt"},
+	}
+	if len(objects) != len(want) {
+		t.Fatalf("want %d objects, but %d", len(want), len(objects))
+	}
+	for i, w := range want {
+		if objects[i].ObjType != w.objType || objects[i].Name != w.name {
+			t.Errorf("want %v %s, but %v %s", w.objType, w.name, objects[i].ObjType, objects[i].Name)
+		}
+	}
+	if got := LocalPath(bucket, objects[2].Name); got != filepath.Join(dir, "file.txt") {
+		t.Errorf("want path %s, but %s", filepath.Join(dir, "file.txt"), got)
+	}
+}
+
+type fakeLister struct{}
+
+func (l *fakeLister) ListBuckets() []*S3Object {
+	return []*S3Object{
+		NewS3Object(Bucket, "other", nil, nil),
+		NewS3Object(Bucket, "bucket", nil, nil),
+	}
+}
+
+func (l *fakeLister) ListObjects(bucket, prefix string) []*S3Object {
+	return []*S3Object{
+		NewS3Object(PreDir, "..", nil, nil),
+		NewS3Object(Dir, prefix+"a/", nil, nil),
+		NewS3Object(Dir, prefix+"b/", nil, nil),
+	}
+}
+
+func (l *fakeLister) IsLocal() bool {
+	return false
+}
+
+func TestNewNodeTree(t *testing.T) {
+	node := NewNodeTree(&fakeLister{}, "bucket", "b/a/")
+	if node.Key != "b/a/" {
+		t.Fatalf("want key b/a/, but %s", node.Key)
+	}
+
+	wantKeys := []string{"b/", "bucket", ""}
+	wantPositions := []int{1, 2, 1}
+	parent := node.Parent
+	for i, key := range wantKeys {
+		if parent.Key != key {
+			t.Errorf("want parent key %q, but %q", key, parent.Key)
+		}
+		if parent.Position != wantPositions[i] {
+			t.Errorf("%q want position %d, but %d", key, wantPositions[i], parent.Position)
+		}
+		parent = parent.Parent
+	}
+	if !node.Parent.Parent.IsBucketRoot() {
+		t.Errorf("want bucket root node")
+	}
+}
+
+func TestNodeAddObject(t *testing.T) {
+	node := NewNode("a/", nil, []*S3Object{
+		NewS3Object(PreDir, "..", nil, nil),
+		NewS3Object(Dir, "a/b/", nil, nil),
+		NewS3Object(Object, "a/c.txt", nil, nil),
+	})
+	tests := []struct {
+		obj  *S3Object
+		want int
+	}{
+		{NewS3Object(Dir, "a/a/", nil, nil), 1},
+		{NewS3Object(Dir, "a/d/", nil, nil), 3},
+		{NewS3Object(Object, "a/z.txt", nil, nil), 5},
+		{NewS3Object(Object, "a/c.txt", nil, nil), 4},
+	}
+	for _, tt := range tests {
+		if got := node.AddObject(tt.obj); got != tt.want {
+			t.Errorf("AddObject(%s) = %d, want %d", tt.obj.Name, got, tt.want)
+		}
+	}
+	if len(node.Objects) != 6 {
+		t.Errorf("len(Objects) = %d, want 6", len(node.Objects))
+	}
+}
+
+// deniedLister fails listing of buckets and parents of "b/", like a user allowed only the prefix.
+type deniedLister struct {
+	fakeLister
+}
+
+func (l *deniedLister) FetchBuckets() ([]*S3Object, error) {
+	return nil, errors.New("access denied")
+}
+
+func (l *deniedLister) FetchObjects(bucket, prefix string) ([]*S3Object, error) {
+	return nil, errors.New("access denied")
+}
+
+func TestNewNodeTreeDenied(t *testing.T) {
+	node := NewNodeTree(&deniedLister{}, "bucket", "b/a")
+	if node.Key != "b/a/" {
+		t.Fatalf("want key b/a/, but %s", node.Key)
+	}
+	for parent, want := node.Parent, node.Key; parent != nil; parent = parent.Parent {
+		if got := parent.Objects[parent.Position].Name; got != want {
+			t.Errorf("%q want cursor on %q, but %q", parent.Key, want, got)
+		}
+		want = parent.Key
+	}
+}
@@ -0,0 +1,290 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Endpoint is source or destination of transfer, on S3 or on local filesystem.
+type Endpoint struct {
+	Local   bool
+	Profile string
+	Bucket  string
+	Key     string
+}
+
+func (e *Endpoint) String() string {
+	if e.Local {
+		return LocalPath(e.Bucket, e.Key)
+	}
+	return "s3://" + e.Bucket + "/" + e.Key
+}
+
+func (e *Endpoint) child(rel string) *Endpoint {
+	return &Endpoint{
+		Local:   e.Local,
+		Profile: e.Profile,
+		Bucket:  e.Bucket,
+		Key:     e.Key + rel,
+	}
+}
+
+type TransferStatus int
+
+const (
+	TransferQueued TransferStatus = iota //0
+	TransferRunning
+	TransferDone
+	TransferFailed
+)
+
+// Transfer copies or moves an object, or a directory recursively, between endpoints.
+// Files existing at local destination are skipped, counted by Skip, not to be overwritten.
+type Transfer struct {
+	Src    *Endpoint
+	Dst    *Endpoint
+	IsDir  bool
+	Move   bool
+	Status TransferStatus
+	Files  int
+	Skip   int
+	Err    error
+}
+
+func NewTransfer(src, dst *Endpoint, isDir, move bool) *Transfer {
+	return &Transfer{
+		Src:   src,
+		Dst:   dst,
+		IsDir: isDir,
+		Move:  move,
+	}
+}
+
+func (t *Transfer) String() string {
+	action := "copy"
+	if t.Move {
+		action = "move"
+	}
+	switch t.Status {
+	case TransferRunning:
+		return fmt.Sprintf("%s %s -> %s (%d files)", action, t.Src, t.Dst, t.Files)
+	case TransferDone:
+		if t.Skip > 0 {
+			return fmt.Sprintf("%s complete %s -> %s (%d files, %d skipped as already exist)", action, t.Src, t.Dst, t.Files, t.Skip)
+		}
+		return fmt.Sprintf("%s complete %s -> %s (%d files)", action, t.Src, t.Dst, t.Files)
+	case TransferFailed:
+		return fmt.Sprintf("%s failed %s, %v", action, t.Src, t.Err)
+	}
+	return fmt.Sprintf("%s queued %s -> %s", action, t.Src, t.Dst)
+}
+
+// TransferQueue runs transfers one by one in background.
+type TransferQueue struct {
+	Items  []*Transfer
+	jobs   chan *Transfer
+	notify func(*Transfer)
+	mutex  sync.Mutex
+}
+
+// NewTransferQueue starts queue worker. notify is called from the worker on status change.
+func NewTransferQueue(notify func(*Transfer)) *TransferQueue {
+	q := &TransferQueue{
+		jobs:   make(chan *Transfer, 1024),
+		notify: notify,
+	}
+	go q.run()
+	return q
+}
+
+func (q *TransferQueue) Add(t *Transfer) {
+	q.mutex.Lock()
+	q.Items = append(q.Items, t)
+	q.mutex.Unlock()
+	q.jobs <- t
+}
+
+func (q *TransferQueue) run() {
+	for t := range q.jobs {
+		t.Status = TransferRunning
+		q.notify(t)
+		if err := t.Execute(q.notify); err != nil {
+			t.Err = err
+			t.Status = TransferFailed
+		} else {
+			t.Status = TransferDone
+		}
+		q.notify(t)
+	}
+}
+
+// Execute runs transfer, calling notify after each file.
+func (t *Transfer) Execute(notify func(*Transfer)) error {
+	rels := []string{""}
+	if t.IsDir {
+		var err error
+		if rels, err = listRelative(t.Src); err != nil {
+			return err
+		}
+	}
+
+	for _, rel := range rels {
+		src, dst := t.Src.child(rel), t.Dst.child(rel)
+		if strings.HasSuffix(src.Key, "/") {
+			if err := transferMarker(src, dst, t.Move); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := transferFile(src, dst); os.IsExist(err) {
+			// existing local files are not overwritten, and kept at source on move
+			t.Skip++
+			notify(t)
+			continue
+		} else if err != nil {
+			return err
+		}
+		if t.Move {
+			if err := removeFile(src); err != nil {
+				return err
+			}
+		}
+		t.Files++
+		notify(t)
+	}
+
+	if t.Move && t.IsDir && t.Src.Local && t.Skip == 0 {
+		return os.RemoveAll(LocalPath(t.Src.Bucket, t.Src.Key))
+	}
+	return nil
+}
+
+// transferMarker creates "directory" marker object at destination, deleting the source on move.
+func transferMarker(src, dst *Endpoint, move bool) error {
+	var err error
+	if dst.Local {
+		err = os.MkdirAll(LocalPath(dst.Bucket, dst.Key), 0755)
+	} else {
+		err = upload(getS3Uploader(dst.Profile), dst.Bucket, dst.Key, bytes.NewReader(nil))
+	}
+	if err != nil || !move {
+		return err
+	}
+	return removeFile(src)
+}
+
+// listRelative lists files under directory endpoint by key relative to it.
+func listRelative(e *Endpoint) ([]string, error) {
+	rels := []string{}
+	if e.Local {
+		root := LocalPath(e.Bucket, e.Key)
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.Mode().IsRegular() {
+				rel, _ := filepath.Rel(root, path)
+				rels = append(rels, filepath.ToSlash(rel))
+			}
+			return nil
+		})
+		return rels, err
+	}
+
+	objects, err := listAllObjects(getS3ClientFor(e.Profile), e.Bucket, e.Key)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objects {
+		// "directory" marker objects are included to be moved with the files
+		rels = append(rels, strings.TrimPrefix(obj.Name, e.Key))
+	}
+	return rels, nil
+}
+
+func transferFile(src, dst *Endpoint) error {
+	switch {
+	case src.Local && dst.Local:
+		in, err := os.Open(LocalPath(src.Bucket, src.Key))
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		return writeLocalFile(dst, func(out *os.File) error {
+			_, err := io.Copy(out, in)
+			return err
+		})
+	case src.Local:
+		in, err := os.Open(LocalPath(src.Bucket, src.Key))
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		return upload(getS3Uploader(dst.Profile), dst.Bucket, dst.Key, in)
+	case dst.Local:
+		return writeLocalFile(dst, func(out *os.File) error {
+			_, err := getS3DownloaderFor(src.Profile).DownloadWithContext(context.Background(), out, &s3.GetObjectInput{
+				Bucket: aws.String(src.Bucket),
+				Key:    aws.String(src.Key),
+			})
+			if err != nil {
+				return fmt.Errorf("failed download, %v", err)
+			}
+			return nil
+		})
+	case src.Profile == dst.Profile:
+		return copyObject(getS3ClientFor(src.Profile), src.Bucket, src.Key, dst.Bucket, dst.Key)
+	}
+
+	// objects cannot be copied between accounts of different profiles by CopyObject
+	result, err := getS3ClientFor(src.Profile).GetObjectWithContext(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(src.Bucket),
+		Key:    aws.String(src.Key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed get object, %v", err)
+	}
+	defer result.Body.Close()
+	return upload(getS3Uploader(dst.Profile), dst.Bucket, dst.Key, result.Body)
+}
+
+// writeLocalFile creates file of e by write. The file is removed when write fails,
+// so that retry of the transfer does not skip it as existing.
+func writeLocalFile(e *Endpoint, write func(*os.File) error) error {
+	out, err := createLocalFile(e)
+	if err != nil {
+		return err
+	}
+	err = write(out)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(out.Name())
+	}
+	return err
+}
+
+func createLocalFile(e *Endpoint) (*os.File, error) {
+	path := LocalPath(e.Bucket, e.Key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+}
+
+func removeFile(e *Endpoint) error {
+	if e.Local {
+		return os.Remove(LocalPath(e.Bucket, e.Key))
+	}
+	return deleteObject(getS3ClientFor(e.Profile), e.Bucket, e.Key)
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransferSkipsExistingLocalFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s3tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, body := range map[string]string{"src/a": "new a", "src/b": "new b", "dst/a": "old a"} {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bucket, key := LocalKey(dir)
+	src := &Endpoint{Local: true, Bucket: bucket, Key: key + "src/"}
+	dst := &Endpoint{Local: true, Bucket: bucket, Key: key + "dst/"}
+	tr := NewTransfer(src, dst, true, true)
+	if err := tr.Execute(func(*Transfer) {}); err != nil {
+		t.Fatal(err)
+	}
+	if tr.Files != 1 || tr.Skip != 1 {
+		t.Errorf("files = %d, skip = %d, want 1 and 1", tr.Files, tr.Skip)
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(dir, "dst/a")); string(b) != "old a" {
+		t.Errorf("existing file is overwritten by %q", b)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "src/a")); err != nil {
+		t.Errorf("skipped file is removed from source on move, %v", err)
+	}
+}
+
+func TestTransferRemovesFailedDownload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s3tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	f := &fakeS3{
+		objects: map[string][]byte{},
+		puts:    map[string]*http.Request{},
+		bodies:  map[string][]byte{},
+	}
+	defer useFakeS3(t, f)()
+
+	bucket, key := LocalKey(dir)
+	src := &Endpoint{Profile: currentProfile(), Bucket: "bucket", Key: "a"}
+	dst := &Endpoint{Local: true, Bucket: bucket, Key: key + "a"}
+	if err := transferFile(src, dst); err == nil {
+		t.Fatal("download of missing object succeeded")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a")); !os.IsNotExist(err) {
+		t.Errorf("failed download is left, %v", err)
+	}
+
+	f.objects["a"] = []byte("a")
+	if err := transferFile(src, dst); err != nil {
+		t.Errorf("retry failed, %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+const s3Scheme = "s3://"
+
+// IsS3URL reports whether s is s3:// URL.
+func IsS3URL(s string) bool {
+	return strings.HasPrefix(s, s3Scheme)
+}
+
+// ParseS3URL splits s3://bucket/key into bucket and key. Key is empty for bucket itself.
+func ParseS3URL(s string) (string, string, error) {
+	if !IsS3URL(s) {
+		return "", "", fmt.Errorf("invalid s3 url %q, must start with %s", s, s3Scheme)
+	}
+	path := strings.TrimPrefix(s, s3Scheme)
+	sp := strings.SplitN(path, "/", 2)
+	if sp[0] == "" {
+		return "", "", fmt.Errorf("invalid s3 url %q, bucket is empty", s)
+	}
+	if len(sp) == 1 {
+		return sp[0], "", nil
+	}
+	return sp[0], sp[1], nil
+}
+
+// S3URL returns s3:// URL of key in bucket.
+func S3URL(bucket, key string) string {
+	return s3Scheme + bucket + "/" + key
+}
+
+// ResolveKey resolves path relative to prefix into key, handling "." and "..".
+// Path with leading slash is absolute. Key of directory keeps trailing slash.
+func ResolveKey(prefix, path string) string {
+	base := prefix
+	if strings.HasPrefix(path, "/") {
+		base = ""
+	}
+	var names []string
+	for _, name := range strings.Split(base+"/"+path, "/") {
+		switch name {
+		case "", ".":
+		case "..":
+			if len(names) > 0 {
+				names = names[:len(names)-1]
+			}
+		default:
+			names = append(names, name)
+		}
+	}
+	key := strings.Join(names, "/")
+	last := path[strings.LastIndex(path, "/")+1:]
+	if key != "" && (last == "" || last == "." || last == "..") {
+		key += "/"
+	}
+	return key
+}
+
+// ResolvePath resolves path typed on prefix of bucket into bucket and key.
+// Path is s3:// URL, absolute in bucket by leading slash, or relative to prefix.
+// On bucket list, that is empty bucket, the first element of path is bucket.
+func ResolvePath(bucket, prefix, path string) (string, string, error) {
+	if IsS3URL(path) {
+		if path == s3Scheme {
+			return "", "", nil
+		}
+		return ParseS3URL(path)
+	}
+	base := ""
+	if bucket != "" {
+		base = bucket + "/"
+		if strings.HasPrefix(path, "/") {
+			path = strings.TrimPrefix(path, "/")
+		} else {
+			base += prefix
+		}
+	}
+	full := ResolveKey(base, path)
+	if full == "" {
+		return "", "", nil
+	}
+	sp := strings.SplitN(full, "/", 2)
+	if len(sp) == 1 {
+		return sp[0], "", nil
+	}
+	return sp[0], sp[1], nil
+}
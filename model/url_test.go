@@ -0,0 +1,62 @@
+package model
+
+import "testing"
+
+func TestParseS3URL(t *testing.T) {
+	tests := []struct {
+		url     string
+		bucket  string
+		key     string
+		wantErr bool
+	}{
+		{"s3://bucket", "bucket", "", false},
+		{"s3://bucket/", "bucket", "", false},
+		{"s3://bucket/logs/2019/", "bucket", "logs/2019/", false},
+		{"s3://bucket/logs/app.log", "bucket", "logs/app.log", false},
+		{"s3://", "", "", true},
+		{"/tmp/app.log", "", "", true},
+	}
+	for _, tt := range tests {
+		bucket, key, err := ParseS3URL(tt.url)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseS3URL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			continue
+		}
+		if bucket != tt.bucket || key != tt.key {
+			t.Errorf("ParseS3URL(%q) = %q, %q, want %q, %q", tt.url, bucket, key, tt.bucket, tt.key)
+		}
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	tests := []struct {
+		bucket string
+		prefix string
+		path   string
+		want   string
+	}{
+		{"bucket", "logs/", "2019/", "s3://bucket/logs/2019/"},
+		{"bucket", "logs/", "2019/app.log", "s3://bucket/logs/2019/app.log"},
+		{"bucket", "logs/2019/", "..", "s3://bucket/logs/"},
+		{"bucket", "logs/2019/", "../2018/", "s3://bucket/logs/2018/"},
+		{"bucket", "logs/2019/", "/data/", "s3://bucket/data/"},
+		{"bucket", "logs/", "../../other/x/", "s3://other/x/"},
+		{"bucket", "logs/", "../..", "s3://"},
+		{"", "", "bucket/logs/", "s3://bucket/logs/"},
+		{"", "", "bucket", "s3://bucket/"},
+		{"bucket", "logs/", "s3://other/data/", "s3://other/data/"},
+	}
+	for _, tt := range tests {
+		bucket, key, err := ResolvePath(tt.bucket, tt.prefix, tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := "s3://"
+		if bucket != "" {
+			got = S3URL(bucket, key)
+		}
+		if got != tt.want {
+			t.Errorf("ResolvePath(%q, %q, %q) = %q, want %q", tt.bucket, tt.prefix, tt.path, got, tt.want)
+		}
+	}
+}
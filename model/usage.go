@@ -0,0 +1,221 @@
+package model
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/lighttiger2505/s3tf/internal"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// LocalStorageClass is storage class of local files in DirUsage.
+const LocalStorageClass = "LOCAL"
+
+type ClassUsage struct {
+	Bytes int64 `yaml:"bytes"`
+	Count int64 `yaml:"count"`
+	// Small and SmallBytes are number and size of objects smaller than SmallObjectSize.
+	Small      int64 `yaml:"small"`
+	SmallBytes int64 `yaml:"small_bytes"`
+}
+
+// DirUsage is total size and number of objects under a prefix, broken down by storage class.
+type DirUsage struct {
+	Bytes        int64                  `yaml:"bytes"`
+	Count        int64                  `yaml:"count"`
+	Classes      map[string]*ClassUsage `yaml:"classes"`
+	CalculatedAt time.Time              `yaml:"calculated_at"`
+}
+
+// NewDirUsage returns empty usage.
+func NewDirUsage() *DirUsage {
+	return &DirUsage{Classes: map[string]*ClassUsage{}}
+}
+
+func (u *DirUsage) add(class string, size int64) {
+	if class == "" {
+		class = "STANDARD"
+	}
+	c, ok := u.Classes[class]
+	if !ok {
+		c = &ClassUsage{}
+		u.Classes[class] = c
+	}
+	c.Bytes += size
+	c.Count++
+	if size < SmallObjectSize {
+		c.Small++
+		c.SmallBytes += size
+	}
+	u.Bytes += size
+	u.Count++
+}
+
+// Merge adds usage of other, e.g. to sum up selected directories.
+func (u *DirUsage) Merge(other *DirUsage) {
+	for name, o := range other.Classes {
+		c, ok := u.Classes[name]
+		if !ok {
+			c = &ClassUsage{}
+			u.Classes[name] = c
+		}
+		c.Bytes += o.Bytes
+		c.Count += o.Count
+		c.Small += o.Small
+		c.SmallBytes += o.SmallBytes
+	}
+	u.Bytes += other.Bytes
+	u.Count += other.Count
+	if u.CalculatedAt.IsZero() || other.CalculatedAt.Before(u.CalculatedAt) {
+		u.CalculatedAt = other.CalculatedAt
+	}
+}
+
+// ClassNames returns storage classes from the largest.
+func (u *DirUsage) ClassNames() []string {
+	names := make([]string, 0, len(u.Classes))
+	for name := range u.Classes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := u.Classes[names[i]], u.Classes[names[j]]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func (u *DirUsage) String() string {
+	return fmt.Sprintf("%s, %d objects", FormatBytes(u.Bytes), u.Count)
+}
+
+// CalculateUsage sums objects under prefix of bucket on S3 of profile.
+// Progress is called with the usage so far after each page.
+func CalculateUsage(profile, bucket, prefix string, progress func(*DirUsage)) (*DirUsage, error) {
+	usage := NewDirUsage()
+	err := walkObjects(getS3ClientFor(profile), bucket, prefix, func(page []*S3Object) bool {
+		for _, obj := range page {
+			var size int64
+			if obj.Size != nil {
+				size = *obj.Size
+			}
+			usage.add(obj.StorageClass, size)
+		}
+		if progress != nil {
+			progress(usage)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	usage.CalculatedAt = time.Now()
+	return usage, nil
+}
+
+// CalculateLocalUsage sums files under key of local filesystem.
+func CalculateLocalUsage(bucket, key string) (*DirUsage, error) {
+	usage := NewDirUsage()
+	err := filepath.Walk(LocalPath(bucket, key), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			usage.add(LocalStorageClass, info.Size())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed walk directory, %v", err)
+	}
+	usage.CalculatedAt = time.Now()
+	return usage, nil
+}
+
+// FormatBytes formats size by binary units, e.g. 1.5 MiB.
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// UsageEntry is calculated usage of location.
+type UsageEntry struct {
+	Location `yaml:",inline"`
+	Usage    *DirUsage `yaml:"usage"`
+}
+
+// usageCacheVersion is raised when DirUsage gets counts which older cached usages lack.
+// Version 1 counts small objects.
+const usageCacheVersion = 1
+
+// UsageCache keeps calculated usages, persisted under XDG cache directory.
+type UsageCache struct {
+	Version int           `yaml:"version"`
+	Items   []*UsageEntry `yaml:"items"`
+}
+
+func (c *UsageCache) Get(loc *Location) *DirUsage {
+	for _, e := range c.Items {
+		if e.Location == *loc {
+			return e.Usage
+		}
+	}
+	return nil
+}
+
+func (c *UsageCache) Put(loc *Location, usage *DirUsage) {
+	for _, e := range c.Items {
+		if e.Location == *loc {
+			e.Usage = usage
+			return
+		}
+	}
+	c.Items = append(c.Items, &UsageEntry{Location: *loc, Usage: usage})
+}
+
+func getUsageCachePath() string {
+	return filepath.Join(internal.GetXDGCachePath(), "usage.yml")
+}
+
+// LoadUsageCache returns usages calculated in past sessions, empty when there is none.
+func LoadUsageCache() (*UsageCache, error) {
+	b, err := ioutil.ReadFile(getUsageCachePath())
+	if os.IsNotExist(err) {
+		return &UsageCache{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &UsageCache{}
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("Failed unmarshal yaml. Error: %s", err.Error())
+	}
+	if c.Version != usageCacheVersion {
+		// usages of older version are calculated again
+		return &UsageCache{}, nil
+	}
+	return c, nil
+}
+
+func SaveUsageCache(c *UsageCache) error {
+	c.Version = usageCacheVersion
+	out, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("Failed marshal config. Error: %v", err.Error())
+	}
+	return ioutil.WriteFile(getUsageCachePath(), out, 0644)
+}
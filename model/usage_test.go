@@ -0,0 +1,68 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024 * 1024, "5.0 GiB"},
+	}
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %s, want %s", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestDirUsageClassNames(t *testing.T) {
+	u := NewDirUsage()
+	u.add("GLACIER", 100)
+	u.add("", 300)
+	u.add("STANDARD_IA", 100)
+	u.add("STANDARD", 10)
+
+	if u.Bytes != 510 || u.Count != 4 {
+		t.Errorf("total %d bytes %d objects", u.Bytes, u.Count)
+	}
+	want := []string{"STANDARD", "GLACIER", "STANDARD_IA"}
+	if got := u.ClassNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("classes %v, want %v", got, want)
+	}
+	if got := u.Classes["STANDARD"]; got.Bytes != 310 || got.Count != 2 {
+		t.Errorf("standard %d bytes %d objects", got.Bytes, got.Count)
+	}
+}
+
+func TestCalculateLocalUsage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s3tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	ioutil.WriteFile(filepath.Join(dir, "a"), make([]byte, 10), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "sub", "b"), make([]byte, 20), 0644)
+
+	bucket, key := LocalKey(dir)
+	u, err := CalculateLocalUsage(bucket, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Bytes != 30 || u.Count != 2 || u.Classes[LocalStorageClass].Count != 2 {
+		t.Errorf("usage %v", u)
+	}
+}
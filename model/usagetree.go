@@ -0,0 +1,183 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// UsageNode is total size of a directory or size of an object in usage tree.
+// Name is the full key, directories have trailing slash.
+type UsageNode struct {
+	Name     string
+	Dir      bool
+	Bytes    int64
+	Count    int64
+	Parent   *UsageNode
+	Children []*UsageNode
+	children map[string]*UsageNode
+}
+
+func newUsageDir(name string, parent *UsageNode) *UsageNode {
+	return &UsageNode{Name: name, Dir: true, Parent: parent, children: map[string]*UsageNode{}}
+}
+
+// add adds object of key under n, creating directories on the way.
+func (n *UsageNode) add(key string, size int64) {
+	node := n
+	rel := strings.TrimPrefix(key, n.Name)
+	for {
+		node.Bytes += size
+		node.Count++
+		i := strings.Index(rel, "/")
+		if i < 0 {
+			break
+		}
+		name := node.Name + rel[:i+1]
+		child, ok := node.children[name]
+		if !ok {
+			child = newUsageDir(name, node)
+			node.children[name] = child
+			node.Children = append(node.Children, child)
+		}
+		node, rel = child, rel[i+1:]
+	}
+	if rel == "" {
+		// directory marker object
+		return
+	}
+	node.Children = append(node.Children, &UsageNode{Name: node.Name + rel, Bytes: size, Count: 1, Parent: node})
+}
+
+// sort orders children from the largest, recursively.
+func (n *UsageNode) sort() {
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		return a.Name < b.Name
+	})
+	for _, child := range n.Children {
+		if child.Dir {
+			child.sort()
+		}
+	}
+}
+
+// Remove removes child from n, subtracting its size from n and the parents.
+func (n *UsageNode) Remove(child *UsageNode) {
+	for i, c := range n.Children {
+		if c == child {
+			n.Children = append(n.Children[:i], n.Children[i+1:]...)
+			break
+		}
+	}
+	delete(n.children, child.Name)
+	for p := n; p != nil; p = p.Parent {
+		p.Bytes -= child.Bytes
+		p.Count -= child.Count
+	}
+}
+
+// Ratio returns share of n in its parent, 1 for root.
+func (n *UsageNode) Ratio() float64 {
+	if n.Parent == nil {
+		return 1
+	}
+	if n.Parent.Bytes == 0 {
+		return 0
+	}
+	return float64(n.Bytes) / float64(n.Parent.Bytes)
+}
+
+// NewUsageTree builds usage tree of objects under prefix.
+func NewUsageTree(prefix string, objects []*S3Object) *UsageNode {
+	root := newUsageDir(prefix, nil)
+	for _, obj := range objects {
+		var size int64
+		if obj.Size != nil {
+			size = *obj.Size
+		}
+		root.add(obj.Name, size)
+	}
+	root.sort()
+	return root
+}
+
+// ErrScanStopped is returned by scan stopped before listing all objects.
+var ErrScanStopped = errors.New("scan stopped")
+
+func stopped(stop <-chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// ScanUsage lists all objects under prefix of bucket on S3 of profile into usage tree.
+// Progress is called with number of objects scanned so far after each page.
+// Closing stop stops listing with ErrScanStopped.
+func ScanUsage(profile, bucket, prefix string, stop <-chan struct{}, progress func(int64)) (*UsageNode, error) {
+	root := newUsageDir(prefix, nil)
+	err := walkObjects(getS3ClientFor(profile), bucket, prefix, func(page []*S3Object) bool {
+		if stopped(stop) {
+			return false
+		}
+		for _, obj := range page {
+			var size int64
+			if obj.Size != nil {
+				size = *obj.Size
+			}
+			root.add(obj.Name, size)
+		}
+		if progress != nil {
+			progress(root.Count)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if stopped(stop) {
+		return nil, ErrScanStopped
+	}
+	root.sort()
+	return root, nil
+}
+
+// ScanLocalUsage walks files under key of local filesystem into usage tree in the same way.
+func ScanLocalUsage(bucket, key string, stop <-chan struct{}) (*UsageNode, error) {
+	root := newUsageDir(key, nil)
+	base := LocalPath(bucket, key)
+	err := filepath.Walk(base, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if stopped(stop) {
+			return ErrScanStopped
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		root.add(key+filepath.ToSlash(rel), info.Size())
+		return nil
+	})
+	if err == ErrScanStopped {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed walk directory, %v", err)
+	}
+	root.sort()
+	return root, nil
+}
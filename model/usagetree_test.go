@@ -0,0 +1,67 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewUsageTree(t *testing.T) {
+	size := func(n int64) *int64 { return &n }
+	root := NewUsageTree("logs/", []*S3Object{
+		{ObjType: Object, Name: "logs/a.txt", Size: size(10)},
+		{ObjType: Object, Name: "logs/2019/", Size: size(0)},
+		{ObjType: Object, Name: "logs/2019/x.gz", Size: size(100)},
+		{ObjType: Object, Name: "logs/2020/01/y.gz", Size: size(300)},
+		{ObjType: Object, Name: "logs/2020/z.gz", Size: size(50)},
+	})
+
+	if root.Bytes != 460 || root.Count != 5 {
+		t.Fatalf("root %d bytes %d objects", root.Bytes, root.Count)
+	}
+	want := []string{"logs/2020/", "logs/2019/", "logs/a.txt"}
+	if len(root.Children) != len(want) {
+		t.Fatalf("children %d, want %d", len(root.Children), len(want))
+	}
+	for i, child := range root.Children {
+		if child.Name != want[i] {
+			t.Errorf("child[%d] %s, want %s", i, child.Name, want[i])
+		}
+	}
+
+	y2020 := root.Children[0]
+	if y2020.Bytes != 350 || !y2020.Dir || y2020.Children[0].Name != "logs/2020/01/" {
+		t.Errorf("2020 %d bytes, first child %s", y2020.Bytes, y2020.Children[0].Name)
+	}
+	if got := y2020.Ratio(); got < 0.76 || got > 0.77 {
+		t.Errorf("ratio %f", got)
+	}
+
+	y2020.Remove(y2020.Children[0])
+	if y2020.Bytes != 50 || root.Bytes != 160 || root.Count != 4 {
+		t.Errorf("after remove 2020 %d bytes, root %d bytes %d objects", y2020.Bytes, root.Bytes, root.Count)
+	}
+}
+
+func TestScanLocalUsageStop(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s3tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "a"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bucket, key := LocalKey(dir)
+
+	stop := make(chan struct{})
+	root, err := ScanLocalUsage(bucket, key, stop)
+	if err != nil || root.Count != 1 {
+		t.Fatalf("scan = %v, %v", root, err)
+	}
+	close(stop)
+	if _, err := ScanLocalUsage(bucket, key, stop); err != ErrScanStopped {
+		t.Errorf("stopped scan returns %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lighttiger2505/s3tf/model"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// usageScan is location explored on usage view.
+type usageScan struct {
+	loc  *model.Location
+	root *model.UsageNode
+	stop chan struct{}
+}
+
+// exploreUsage scans directory on the cursor, or the current one, and shows it like ncdu.
+func (p *Provider) exploreUsage() {
+	loc := p.usageLocation(p.listView.GetCursorObject())
+	if loc == nil {
+		if p.node.IsRoot() {
+			p.statusView.Msg = "select bucket or directory to explore"
+			return
+		}
+		loc = &model.Location{Profile: p.profile, Bucket: p.bucket, Key: p.node.Prefix()}
+		if p.lister.IsLocal() {
+			loc.Profile, loc.Local = "", true
+		}
+	}
+	if !loc.Local && !p.isOnline() {
+		return
+	}
+
+	p.stopScan()
+	scan := &usageScan{loc: loc, stop: make(chan struct{})}
+	p.scan = scan
+	p.status = StateUsage
+	p.usageView.SetRoot(nil)
+	p.statusView.Msg = fmt.Sprintf("scanning. %s", loc)
+	go func() {
+		var root *model.UsageNode
+		var err error
+		if loc.Local {
+			root, err = model.ScanLocalUsage(loc.Bucket, loc.Key, scan.stop)
+		} else {
+			root, err = model.ScanUsage(loc.Profile, loc.Bucket, loc.Key, scan.stop, func(count int64) {
+				p.post(func() {
+					if p.scan == scan {
+						p.statusView.Msg = fmt.Sprintf("scanning. %s %d objects", loc, count)
+					}
+				})
+			})
+		}
+		p.post(func() {
+			if p.scan != scan {
+				return
+			}
+			if err != nil {
+				p.scan = nil
+				p.status = StateList
+				p.statusView.Msg = fmt.Sprintf("failed scan %s, %v", loc, err)
+				return
+			}
+			scan.root = root
+			p.usageView.SetRoot(root)
+			p.statusView.Msg = p.usageTitle()
+		})
+	}()
+}
+
+// stopScan stops listing of the running scan, which is not shown any more.
+func (p *Provider) stopScan() {
+	if s := p.scan; s != nil && s.root == nil {
+		select {
+		case <-s.stop:
+		default:
+			close(s.stop)
+		}
+	}
+}
+
+func (p *Provider) usageTitle() string {
+	current := p.usageView.Current
+	loc := *p.scan.loc
+	loc.Key = current.Name
+	return fmt.Sprintf("ncdu. %s %s, %d objects", loc.String(), model.FormatBytes(current.Bytes), current.Count)
+}
+
+// deleteUsageNode deletes object or directory on the cursor of usage view after confirmation.
+func (p *Provider) deleteUsageNode() {
+	node := p.usageView.GetCursorNode()
+	if node == nil {
+		return
+	}
+	scan := p.scan
+	loc := scan.loc
+	label := fmt.Sprintf("delete %s (%s, %d objects)? (y/n): ", node.Name, model.FormatBytes(node.Bytes), node.Count)
+	p.prompt(label, "", func(answer string) {
+		if answer != "y" {
+			p.statusView.Msg = "abort delete"
+			return
+		}
+		p.statusView.Msg = fmt.Sprintf("deleting. %s", node.Name)
+		go func() {
+			var err error
+			switch {
+			case loc.Local:
+				err = os.RemoveAll(model.LocalPath(loc.Bucket, node.Name))
+			case node.Dir:
+				err = model.DeleteAll(loc.Profile, loc.Bucket, node.Name, func(string) {})
+			default:
+				err = model.DeleteKeys(loc.Profile, loc.Bucket, []string{node.Name})
+			}
+			p.post(func() {
+				p.invalidateKey(loc.Local, loc.Profile, loc.Bucket, node.Name)
+				if err != nil {
+					p.statusView.Msg = fmt.Sprintf("failed delete %s, %v", node.Name, err)
+					return
+				}
+				node.Parent.Remove(node)
+				if p.scan == scan {
+					p.usageView.Removed()
+				}
+				p.statusView.Msg = fmt.Sprintf("delete. %s", node.Name)
+			})
+		}()
+	})
+}
+
+func (p *Provider) usageEvent(ev termbox.Event) {
+	ea := getEventAction(ev, chMapOnUsage, keyMapOnUsage)
+	if ea == "" {
+		p.statusView.Msg = "no mapping key"
+		return
+	}
+
+	switch ea {
+	case actQuit:
+		p.stopScan()
+		p.scan = nil
+		p.status = StateList
+		return
+	case actUp:
+		p.usageView.Up()
+	case actDown:
+		p.usageView.Down()
+	case actHalfUp:
+		p.usageView.HalfPageUp()
+	case actHalfDown:
+		p.usageView.HalfPageDown()
+	case actMoveNextDir:
+		p.usageView.Enter()
+	case actMovePrevDir:
+		p.usageView.Leave()
+	case actDeleteUsage:
+		if p.scan.root != nil {
+			p.deleteUsageNode()
+		}
+		return
+	default:
+	}
+	if p.scan.root != nil {
+		p.statusView.Msg = p.usageTitle()
+	}
+}
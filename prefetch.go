@@ -0,0 +1,76 @@
+package main
+
+import (
+	"time"
+
+	"github.com/lighttiger2505/s3tf/model"
+)
+
+const (
+	prefetchWorkers = 4
+	// prefetchInterval limits prefetch to 10 requests per second.
+	prefetchInterval = 100 * time.Millisecond
+	// prefetchNeighbours is number of entries around the cursor to prefetch.
+	prefetchNeighbours = 2
+)
+
+type prefetchKey struct {
+	node *model.Node
+	key  string
+}
+
+func (p *Provider) startPrefetcher() {
+	p.prefetching = map[prefetchKey]bool{}
+	p.prefetcher = model.NewPrefetcher(prefetchWorkers, prefetchInterval, func(job *model.PrefetchJob) {
+		p.post(func() {
+			p.prefetched(job)
+		})
+	})
+}
+
+// prefetch requests listings of directories under and around the cursor not visited yet.
+func (p *Provider) prefetch() {
+	if p.prefetcher == nil || p.prefetcher.Disabled() || p.lister.IsLocal() {
+		return
+	}
+	if l, ok := p.lister.(*model.CacheLister); ok && l.Offline() {
+		return
+	}
+	cursor := p.listView.Cursor()
+	objects := p.node.Objects
+	for i := cursor - prefetchNeighbours; i <= cursor+prefetchNeighbours; i++ {
+		if i < 0 || i >= len(objects) {
+			continue
+		}
+		obj := objects[i]
+		if obj.ObjType != model.Dir && obj.ObjType != model.Bucket {
+			continue
+		}
+		key := prefetchKey{p.node, obj.Name}
+		if p.node.IsExistChildren(obj.Name) || p.prefetching[key] {
+			continue
+		}
+		bucket := p.bucket
+		if obj.ObjType == model.Bucket {
+			bucket = obj.Name
+		}
+		job := &model.PrefetchJob{Lister: p.lister, Bucket: bucket, Node: p.node, Key: obj.Name}
+		if p.prefetcher.Request(job) {
+			p.prefetching[key] = true
+		}
+	}
+}
+
+// prefetched stores prefetched listing in the node tree, unless it has been visited meanwhile.
+func (p *Provider) prefetched(job *model.PrefetchJob) {
+	delete(p.prefetching, prefetchKey{job.Node, job.Key})
+	if job.Err != nil {
+		if model.IsThrottled(job.Err) {
+			p.statusView.Msg = "prefetch disabled by throttling"
+		}
+		return
+	}
+	if !job.Node.IsExistChildren(job.Key) {
+		job.Node.AddChild(job.Key, model.NewNode(job.Key, job.Node, job.Objects))
+	}
+}
@@ -1,14 +1,17 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/lighttiger2505/s3tf/model"
 	"github.com/lighttiger2505/s3tf/view"
 	termbox "github.com/nsf/termbox-go"
@@ -30,12 +33,82 @@ const (
 	actDownloadObject = "download-object"
 	actOpenObject     = "open-object"
 	actEditObject     = "edit-object"
+	actViewObject     = "view-object"
+	actPageObject     = "page-object"
+	actNewFile        = "new-file"
+	actMakeDir        = "make-dir"
+	actEditProperties = "edit-properties"
+	actToggleMark     = "toggle-mark"
+	actBucketConfig   = "bucket-config"
+	actExportListing  = "export-listing"
+	actGoTo           = "go-to"
+	actCalculateUsage = "calculate-usage"
+	actExploreUsage   = "explore-usage"
+	actEstimateCost   = "estimate-cost"
+	actSearchKeys     = "search-keys"
+	actOpenSearch     = "open-search"
+	actAddBookmark    = "add-bookmark"
+	actBack           = "back"
+	actForward        = "forward"
+	actNewTab         = "new-tab"
+	actCloseTab       = "close-tab"
+	actNextTab        = "next-tab"
+	actPrevTab        = "prev-tab"
+	// preview pane
+	actTogglePreview     = "toggle-preview"
+	actScrollPreviewUp   = "scroll-preview-up"
+	actScrollPreviewDown = "scroll-preview-down"
+	actToggleMiller      = "toggle-miller"
+	// commander mode
+	actToggleCommander = "toggle-commander"
+	actSwitchPane      = "switch-pane"
+	actCopyToPane      = "copy-to-pane"
+	actMoveToPane      = "move-to-pane"
+	actToggleLocal     = "toggle-local"
+	actSwitchProfile   = "switch-profile"
 	// move view
 	actOpenMenu     = "open-menu"
 	actOpenDetail   = "open-detail"
 	actOpenDownload = "open-download"
+	actOpenBookmark = "open-bookmark"
+	actOpenHistory  = "open-history"
 	// Menu view action
 	actDoMenuAction = "do-menu-action"
+	// Viewer action
+	actLeft       = "left"
+	actRight      = "right"
+	actToggleFold = "toggle-fold"
+	// Pager action
+	actPageUp         = "page-up"
+	actPageDown       = "page-down"
+	actTop            = "top"
+	actBottom         = "bottom"
+	actSearch         = "search"
+	actSearchBackward = "search-backward"
+	actSearchNext     = "search-next"
+	actSearchPrev     = "search-prev"
+	actJumpLine       = "jump-line"
+	actJumpOffset     = "jump-offset"
+	actToggleFollow   = "toggle-follow"
+	actToggleWrap     = "toggle-wrap"
+	// Review action
+	actApply     = "apply"
+	actEditAgain = "edit-again"
+	// Bookmark action
+	actJumpBookmark    = "jump-bookmark"
+	actDeleteBookmark  = "delete-bookmark"
+	actImportBookmarks = "import-bookmarks"
+	actExportBookmarks = "export-bookmarks"
+	// History action
+	actJumpHistory   = "jump-history"
+	actDeleteHistory = "delete-history"
+	actToggleRank    = "toggle-rank"
+	// Usage explorer action
+	actDeleteUsage = "delete-usage"
+	// Search action
+	actJumpSearch      = "jump-search"
+	actStopSearch      = "stop-search"
+	actDownloadMatches = "download-matches"
 )
 
 var chMapOnList = map[rune]eventAction{
@@ -48,8 +121,38 @@ var chMapOnList = map[rune]eventAction{
 	'w': actDownloadObject,
 	'o': actOpenObject,
 	'e': actEditObject,
+	'v': actViewObject,
+	'P': actPageObject,
 	'm': actOpenMenu,
 	'n': actOpenDownload,
+	'p': actTogglePreview,
+	'K': actScrollPreviewUp,
+	'J': actScrollPreviewDown,
+	'M': actToggleMiller,
+	'C': actToggleCommander,
+	'c': actCopyToPane,
+	'x': actMoveToPane,
+	'L': actToggleLocal,
+	'A': actSwitchProfile,
+	'N': actNewFile,
+	'+': actMakeDir,
+	'E': actEditProperties,
+	'B': actBucketConfig,
+	'X': actExportListing,
+	'g': actGoTo,
+	'a': actAddBookmark,
+	'b': actOpenBookmark,
+	'[': actBack,
+	']': actForward,
+	'H': actOpenHistory,
+	't': actNewTab,
+	'}': actNextTab,
+	'{': actPrevTab,
+	'U': actCalculateUsage,
+	'D': actExploreUsage,
+	'$': actEstimateCost,
+	'/': actSearchKeys,
+	'F': actOpenSearch,
 }
 var keyMapOnList = map[termbox.Key]eventAction{
 	termbox.KeyEsc:       actQuit,
@@ -60,6 +163,12 @@ var keyMapOnList = map[termbox.Key]eventAction{
 	termbox.KeyCtrlU:     actHalfUp,
 	termbox.KeyCtrlD:     actHalfDown,
 	termbox.KeyEnter:     actMoveNextDir,
+	termbox.KeyTab:       actSwitchPane,
+	termbox.KeyF5:        actCopyToPane,
+	termbox.KeyF6:        actMoveToPane,
+	termbox.KeySpace:     actToggleMark,
+	termbox.KeyCtrlO:     actBack,
+	termbox.KeyCtrlW:     actCloseTab,
 }
 var chMapOnMenu = map[rune]eventAction{
 	'q': actQuit,
@@ -105,6 +214,156 @@ var keyMapOnDownload = map[termbox.Key]eventAction{
 	termbox.KeyCtrlU:     actHalfUp,
 	termbox.KeyCtrlD:     actHalfDown,
 }
+var chMapOnBookmark = map[rune]eventAction{
+	'q': actQuit,
+	'b': actQuit,
+	'k': actUp,
+	'j': actDown,
+	'l': actJumpBookmark,
+	'a': actAddBookmark,
+	'd': actDeleteBookmark,
+	'i': actImportBookmarks,
+	'x': actExportBookmarks,
+}
+var keyMapOnBookmark = map[termbox.Key]eventAction{
+	termbox.KeyEsc:       actQuit,
+	termbox.KeyArrowUp:   actUp,
+	termbox.KeyCtrlP:     actUp,
+	termbox.KeyArrowDown: actDown,
+	termbox.KeyCtrlN:     actDown,
+	termbox.KeyCtrlU:     actHalfUp,
+	termbox.KeyCtrlD:     actHalfDown,
+	termbox.KeyEnter:     actJumpBookmark,
+}
+var chMapOnHistory = map[rune]eventAction{
+	'q': actQuit,
+	'H': actQuit,
+	'k': actUp,
+	'j': actDown,
+	'l': actJumpHistory,
+	'd': actDeleteHistory,
+	's': actToggleRank,
+}
+var keyMapOnHistory = map[termbox.Key]eventAction{
+	termbox.KeyEsc:       actQuit,
+	termbox.KeyArrowUp:   actUp,
+	termbox.KeyCtrlP:     actUp,
+	termbox.KeyArrowDown: actDown,
+	termbox.KeyCtrlN:     actDown,
+	termbox.KeyCtrlU:     actHalfUp,
+	termbox.KeyCtrlD:     actHalfDown,
+	termbox.KeyEnter:     actJumpHistory,
+}
+var chMapOnUsage = map[rune]eventAction{
+	'q': actQuit,
+	'k': actUp,
+	'j': actDown,
+	'h': actMovePrevDir,
+	'l': actMoveNextDir,
+	'd': actDeleteUsage,
+}
+var keyMapOnUsage = map[termbox.Key]eventAction{
+	termbox.KeyEsc:        actQuit,
+	termbox.KeyArrowUp:    actUp,
+	termbox.KeyCtrlP:      actUp,
+	termbox.KeyArrowDown:  actDown,
+	termbox.KeyCtrlN:      actDown,
+	termbox.KeyCtrlU:      actHalfUp,
+	termbox.KeyCtrlD:      actHalfDown,
+	termbox.KeyArrowLeft:  actMovePrevDir,
+	termbox.KeyArrowRight: actMoveNextDir,
+	termbox.KeyEnter:      actMoveNextDir,
+}
+var chMapOnSearch = map[rune]eventAction{
+	'q': actQuit,
+	'F': actQuit,
+	'k': actUp,
+	'j': actDown,
+	'l': actJumpSearch,
+	's': actStopSearch,
+	'w': actDownloadMatches,
+	'/': actSearchKeys,
+}
+var keyMapOnSearch = map[termbox.Key]eventAction{
+	termbox.KeyEsc:       actQuit,
+	termbox.KeyArrowUp:   actUp,
+	termbox.KeyCtrlP:     actUp,
+	termbox.KeyArrowDown: actDown,
+	termbox.KeyCtrlN:     actDown,
+	termbox.KeyCtrlU:     actHalfUp,
+	termbox.KeyCtrlD:     actHalfDown,
+	termbox.KeyCtrlC:     actStopSearch,
+	termbox.KeyEnter:     actJumpSearch,
+}
+
+var chMapOnViewer = map[rune]eventAction{
+	'q': actQuit,
+	'k': actUp,
+	'j': actDown,
+	'h': actLeft,
+	'l': actRight,
+	'z': actToggleFold,
+	' ': actToggleFold,
+}
+var keyMapOnViewer = map[termbox.Key]eventAction{
+	termbox.KeyEsc:        actQuit,
+	termbox.KeyArrowUp:    actUp,
+	termbox.KeyCtrlP:      actUp,
+	termbox.KeyArrowDown:  actDown,
+	termbox.KeyCtrlN:      actDown,
+	termbox.KeyCtrlU:      actHalfUp,
+	termbox.KeyCtrlD:      actHalfDown,
+	termbox.KeyArrowLeft:  actLeft,
+	termbox.KeyArrowRight: actRight,
+	termbox.KeyTab:        actToggleFold,
+}
+
+var chMapOnReview = map[rune]eventAction{
+	'q': actQuit,
+	'n': actQuit,
+	'k': actUp,
+	'j': actDown,
+	'h': actLeft,
+	'l': actRight,
+	'y': actApply,
+	'e': actEditAgain,
+}
+
+var chMapOnPager = map[rune]eventAction{
+	'q': actQuit,
+	'k': actUp,
+	'j': actDown,
+	'h': actLeft,
+	'l': actRight,
+	'b': actPageUp,
+	'f': actPageDown,
+	' ': actPageDown,
+	'g': actTop,
+	'G': actBottom,
+	'/': actSearch,
+	'?': actSearchBackward,
+	'n': actSearchNext,
+	'N': actSearchPrev,
+	':': actJumpLine,
+	'@': actJumpOffset,
+	'F': actToggleFollow,
+	'w': actToggleWrap,
+}
+var keyMapOnPager = map[termbox.Key]eventAction{
+	termbox.KeyEsc:        actQuit,
+	termbox.KeyArrowUp:    actUp,
+	termbox.KeyCtrlP:      actUp,
+	termbox.KeyArrowDown:  actDown,
+	termbox.KeyCtrlN:      actDown,
+	termbox.KeyCtrlU:      actHalfUp,
+	termbox.KeyCtrlD:      actHalfDown,
+	termbox.KeyCtrlB:      actPageUp,
+	termbox.KeyCtrlF:      actPageDown,
+	termbox.KeyPgup:       actPageUp,
+	termbox.KeyPgdn:       actPageDown,
+	termbox.KeyArrowLeft:  actLeft,
+	termbox.KeyArrowRight: actRight,
+}
 
 func getEventAction(
 	ev termbox.Event,
@@ -132,39 +391,162 @@ const (
 	StateMenu
 	StateDetail
 	StateDownload
+	StateViewer
+	StatePager
+	StatePrompt
+	StateReview
+	StateBookmark
+	StateHistory
+	StateUsage
+	StateSearch
 )
 
+// followInterval is interval to poll the object on pager follow mode.
+const followInterval = 2 * time.Second
+
+type ProviderOption struct {
+	Profile string
+	Preview bool
+	Miller  bool
+	// Location is where to start, bucket list when nil.
+	Location *model.Location
+	// CacheTTL is how long listings are shown without revalidation, zero for ever.
+	CacheTTL time.Duration
+	// NoPrefetch disables background listing of directories around the cursor.
+	NoPrefetch bool
+	// AutoUsage calculates size of directories on listing in background.
+	AutoUsage bool
+}
+
 type Provider struct {
 	EventHandler
 	status         ProviderStatus
+	lister         model.Lister
+	profile        string
 	node           *model.Node
 	bucket         string
+	other          *pane
+	commander      bool
+	activeLeft     bool
+	transferQueue  *model.TransferQueue
 	dllFile        *model.DownloadListFile
+	bookmarks      *model.BookmarkFile
+	history        *model.HistoryFile
+	navigation     *model.Navigation
+	visited        *model.Location
+	cacheTTL       time.Duration
+	revalidating   map[*model.Node]bool
+	listingCache   *model.ListingCache
+	prefetcher     *model.Prefetcher
+	prefetching    map[prefetchKey]bool
+	usages         *model.UsageCache
+	calculating    map[model.Location]bool
+	usageFailed    map[model.Location]bool
+	autoUsage      bool
+	scan           *usageScan
+	search         *keySearch
+	regions        map[string]string
+	tabs           []*pane
+	tab            int
+	preview        bool
+	miller         bool
+	previewKey     string
+	asyncCh        chan func()
+	quit           bool
 	listView       *view.ListView
+	parentView     *view.ListView
+	childView      *view.ListView
 	navigationView *view.NavigationView
+	tabView        *view.TabView
 	statusView     *view.StatusView
 	menuView       *view.MenuView
 	detailView     *view.DetailView
 	downloadView   *view.DownloadView
+	bookmarkView   *view.BookmarkView
+	historyView    *view.HistoryView
+	usageView      *view.UsageView
+	searchView     *view.SearchView
+	previewView    *view.PreviewView
+	viewerView     *view.ViewerView
+	review         *configReview
+	pagerView      *view.PagerView
+	pagerReader    *model.ObjectReader
+	followStop     chan struct{}
+	promptView     *view.PromptView
+	promptStatus   ProviderStatus
+	promptFn       func(string)
+	// promptComplete completes prompt text by tab, returning candidates when ambiguous.
+	promptComplete func(string) (string, []string)
 }
 
-func NewProvider() *Provider {
-	p := &Provider{}
+func NewProvider(option *ProviderOption) *Provider {
+	p := &Provider{
+		profile: option.Profile,
+		preview: option.Preview,
+		miller:  option.Miller,
+		asyncCh: make(chan func(), 64),
+
+		cacheTTL:     option.CacheTTL,
+		revalidating: map[*model.Node]bool{},
+		calculating:  map[model.Location]bool{},
+		usageFailed:  map[model.Location]bool{},
+		regions:      map[string]string{},
+		autoUsage:    option.AutoUsage,
+	}
+	if loc := option.Location; loc != nil && loc.Profile != "" {
+		p.profile = loc.Profile
+	}
 	p.Init()
+	if !option.NoPrefetch {
+		p.startPrefetcher()
+	}
+	// show cached listings at once, and revalidate them in background
+	p.setPreferCache(true)
+	p.openLocation(option.Location)
+	p.setPreferCache(false)
+	p.tabs = []*pane{p.activePane()}
+	p.Resize()
 	p.Update()
 	p.Draw()
 	return p
 }
 
+// openLocation shows location with its parents, or bucket list for nil.
+func (p *Provider) openLocation(loc *model.Location) {
+	if loc == nil {
+		p.setLocation(p.lister, "", "")
+		return
+	}
+	lister := p.lister
+	if loc.Local {
+		lister = model.NewLocalLister()
+	}
+	p.setLocation(lister, loc.Bucket, loc.Key)
+}
+
+// Location returns location shown on the active pane.
+func (p *Provider) Location() *model.Location {
+	loc := &model.Location{
+		Profile: p.profile,
+		Local:   p.lister.IsLocal(),
+	}
+	if !p.node.IsRoot() {
+		loc.Bucket = p.bucket
+		loc.Key = p.node.Prefix()
+	}
+	return loc
+}
+
 func (p *Provider) Init() {
 	// Init s3 data structure
-	rootNode := model.NewNode("", nil, model.ListBuckets())
+	model.SetProfile(p.profile)
+	p.listingCache = model.NewListingCache()
+	p.lister = p.newS3Lister(p.profile)
 	width, height := termbox.Size()
 	halfWidth := width / 2
 	halfHeight := height / 2
 
 	p.status = StateList
-	p.node = rootNode
 	dllFile, err := model.LoadDownloadFile()
 	if err != nil {
 		panic("failed load download list file.")
@@ -172,13 +554,50 @@ func (p *Provider) Init() {
 	p.dllFile = dllFile
 
 	p.listView = view.NewListView(0, 1, width, height-2)
-	p.listView.Objects = p.node.Objects
-	p.listView.Key = p.node.Key
+	p.parentView = view.NewListView(0, 1, width, height-2)
+	p.childView = view.NewListView(0, 1, width, height-2)
 	p.navigationView = view.NewNavigationView(0, 0, width, 1)
+	p.tabView = view.NewTabView(0, 0, width, 1)
 	p.statusView = view.NewStatusView(0, height-1, width, 1)
 	p.menuView = view.NewMenuView(0, halfHeight, width, height-halfHeight)
 	p.detailView = view.NewDetailView(halfWidth, 1, width-halfWidth, height-2)
 	p.downloadView = view.NewDownloadView(0, 1, width, height-2)
+	p.bookmarkView = view.NewBookmarkView(0, 1, width, height-2)
+	p.historyView = view.NewHistoryView(0, 1, width, height-2)
+	p.usageView = view.NewUsageView(0, 1, width, height-2)
+	p.searchView = view.NewSearchView(0, 1, width, height-2)
+	p.previewView = view.NewPreviewView(halfWidth, 1, width-halfWidth, height-2)
+	p.viewerView = view.NewViewerView(0, 1, width, height-2)
+	p.pagerView = view.NewPagerView(0, 1, width, height-2)
+	p.promptView = view.NewPromptView(0, height-1, width, 1)
+	bookmarks, err := model.LoadBookmarkFile()
+	if err != nil {
+		p.statusView.Msg = fmt.Sprintf("failed load bookmarks, %v", err)
+		bookmarks = &model.BookmarkFile{}
+	}
+	p.bookmarks = bookmarks
+	history, err := model.LoadHistoryFile()
+	if err != nil {
+		p.statusView.Msg = fmt.Sprintf("failed load history, %v", err)
+		history = &model.HistoryFile{}
+	}
+	p.history = history
+	p.navigation = &model.Navigation{}
+	usages, err := model.LoadUsageCache()
+	if err != nil {
+		p.statusView.Msg = fmt.Sprintf("failed load usage cache, %v", err)
+		usages = &model.UsageCache{}
+	}
+	p.usages = usages
+	p.transferQueue = model.NewTransferQueue(func(t *model.Transfer) {
+		msg, finished := t.String(), t.Status == model.TransferDone || t.Status == model.TransferFailed
+		p.post(func() {
+			p.statusView.Msg = msg
+			if finished {
+				p.invalidateTransfer(t)
+			}
+		})
+	})
 }
 
 func (p *Provider) Loop() {
@@ -190,15 +609,61 @@ func (p *Provider) Loop() {
 		case termbox.EventError:
 			panic(ev.Err)
 		case termbox.EventInterrupt:
-			return
+			if p.quit {
+				return
+			}
+			p.drainAsync()
+			p.Update()
 		}
 		p.Resize()
 		p.Draw()
 	}
 }
 
+// post queues fn to run on the event loop and wakes it up.
+// Use this from goroutines instead of touching views directly.
+func (p *Provider) post(fn func()) {
+	p.asyncCh <- fn
+	termbox.Interrupt()
+}
+
+func (p *Provider) drainAsync() {
+	for {
+		select {
+		case fn := <-p.asyncCh:
+			fn()
+		default:
+			return
+		}
+	}
+}
+
 func (p *Provider) Update() {
-	p.navigationView.SetCurrentPath(p.bucket, p.node)
+	p.recordVisit()
+	p.updateTabView()
+	if p.lister.IsLocal() {
+		p.navigationView.SetLocalPath(p.node)
+	} else {
+		p.navigationView.SetCurrentPath(p.bucket, p.node)
+	}
+	p.markCached(p.lister, p.node)
+	p.navigationView.Stale = p.node.IsStale(p.cacheTTL)
+	p.navigationView.CachedAt = p.node.CachedAt
+	p.revalidate(p.lister, p.bucket, p.node)
+	if p.status == StateList {
+		p.prefetch()
+	}
+	p.updateUsages()
+	if p.commander {
+		p.updateOtherPane()
+		return
+	}
+	if (p.preview || p.miller) && p.status == StateList {
+		p.requestPreview()
+	}
+	if p.miller && !p.node.IsRoot() {
+		p.parentView.UpdateList(p.node.Parent)
+	}
 }
 
 func (p *Provider) Resize() {
@@ -206,46 +671,118 @@ func (p *Provider) Resize() {
 	halfWidth := width / 2
 	halfHeight := height / 2
 
-	p.listView.Layer.Resize(0, 1, width, height-2)
-	p.navigationView.Win.Resize(0, 0, width, 1)
+	// navigation line is below tab bar, shown with multiple tabs
+	top := 0
+	if len(p.tabs) > 1 {
+		top = 1
+	}
+	bodyY, bodyHeight := top+1, height-top-2
+
+	listWidth := width
+	if p.preview {
+		listWidth = halfWidth
+	}
+	p.tabView.Win.Resize(0, 0, width, 1)
+	p.listView.Layer.Resize(0, bodyY, listWidth, bodyHeight)
+	p.navigationView.Win.Resize(0, top, width, 1)
+	if p.commander {
+		p.resizePanes(width, top, bodyHeight)
+	} else if p.miller {
+		// parent, current and preview columns by 1:2:2
+		parentWidth := width / 5
+		currentWidth := width * 2 / 5
+		previewX := parentWidth + currentWidth
+		p.parentView.Layer.Resize(0, bodyY, parentWidth-1, bodyHeight)
+		p.listView.Layer.Resize(parentWidth, bodyY, currentWidth-1, bodyHeight)
+		p.childView.Layer.Resize(previewX, bodyY, width-previewX, bodyHeight)
+		p.previewView.Layer.Resize(previewX, bodyY, width-previewX, bodyHeight)
+	}
 	p.statusView.Win.Resize(0, height-1, width, 1)
 	p.menuView.Layer.Resize(0, halfHeight, width, height-halfHeight)
-	p.detailView.Layer.Resize(halfWidth, 1, width-halfWidth, height-2)
-	p.downloadView.Layer.Resize(0, 1, width, height-2)
+	p.detailView.Layer.Resize(halfWidth, bodyY, width-halfWidth, bodyHeight)
+	p.downloadView.Layer.Resize(0, bodyY, width, bodyHeight)
+	p.bookmarkView.Layer.Resize(0, bodyY, width, bodyHeight)
+	p.historyView.Layer.Resize(0, bodyY, width, bodyHeight)
+	p.usageView.Layer.Resize(0, bodyY, width, bodyHeight)
+	p.searchView.Layer.Resize(0, bodyY, width, bodyHeight)
+	if !p.miller {
+		p.previewView.Layer.Resize(halfWidth, bodyY, width-halfWidth, bodyHeight)
+	}
+	p.viewerView.Layer.Resize(0, bodyY, width, bodyHeight)
+	p.pagerView.Layer.Resize(0, bodyY, width, bodyHeight)
+	p.promptView.Win.Resize(0, height-1, width, 1)
 }
 
 func (p *Provider) Draw() {
 	termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
 	defer termbox.Flush()
+
+	// prompt is drawn over the view it was opened from
+	status := p.status
+	if status == StatePrompt {
+		status = p.promptStatus
+	}
+
+	if len(p.tabs) > 1 {
+		p.tabView.Draw()
+	}
 	p.listView.Draw()
 	p.navigationView.Draw()
-	if p.status == StateMenu {
+	if p.commander {
+		p.other.listView.Draw()
+		p.other.navigationView.Draw()
+	} else if p.miller {
+		p.drawColumns()
+	} else if p.preview {
+		p.previewView.Draw()
+	}
+	if status == StateMenu {
 		p.menuView.Draw()
 	}
-	if p.status == StateDetail {
+	if status == StateDetail {
 		p.detailView.Draw()
 	}
-	if p.status == StateDownload {
+	if status == StateDownload {
 		p.downloadView.Draw()
 	}
-	p.statusView.Draw()
+	if status == StateBookmark {
+		p.bookmarkView.Draw()
+	}
+	if status == StateHistory {
+		p.historyView.Draw()
+	}
+	if status == StateUsage {
+		p.usageView.Draw()
+	}
+	if status == StateSearch {
+		p.searchView.Draw()
+	}
+	if status == StateViewer || status == StateReview {
+		p.viewerView.Draw()
+	}
+	if status == StatePager {
+		p.pagerView.Draw()
+	}
+	if p.status == StatePrompt {
+		p.promptView.Draw()
+	} else {
+		p.statusView.Draw()
+	}
 }
 
 func (p *Provider) reload() {
-	if p.node.IsRoot() {
-		p.node.Objects = model.ListBuckets()
-		p.listView.Objects = p.node.Objects
-		return
-	}
+	delete(p.revalidating, p.node)
+	p.node.Position = p.listView.Cursor()
+	reloadNode(p.lister, p.bucket, p.node)
+	p.listView.UpdateList(p.node)
+}
 
-	if p.node.IsBucketRoot() {
-		p.node.Objects = model.ListObjects(p.bucket, "")
-		p.listView.Objects = p.node.Objects
+func reloadNode(lister model.Lister, bucket string, node *model.Node) {
+	if node.IsRoot() {
+		node.Refresh(lister.ListBuckets())
 		return
 	}
-
-	p.node.Objects = model.ListObjects(p.bucket, p.node.Key)
-	p.listView.Objects = p.node.Objects
+	node.Refresh(lister.ListObjects(bucket, node.Prefix()))
 }
 
 func (p *Provider) download() {
@@ -283,20 +820,53 @@ func (p *Provider) download() {
 	}
 }
 
+// exportListing writes the shown listing to local file, in format chosen by its extension.
+func (p *Provider) exportListing() {
+	name := "buckets"
+	if !p.node.IsRoot() {
+		name = strings.Trim(strings.Replace(p.bucket+"/"+p.node.Prefix(), "/", "_", -1), "_")
+	}
+	currentDir, _ := os.Getwd()
+	objects := p.listView.Objects
+	p.prompt("export to: ", filepath.Join(currentDir, name+".csv"), func(path string) {
+		f, err := os.Create(path)
+		if err != nil {
+			p.statusView.Msg = err.Error()
+			return
+		}
+		defer f.Close()
+		if err := model.WriteListing(f, model.OutputFormatOf(path), objects); err != nil {
+			p.statusView.Msg = err.Error()
+			return
+		}
+		p.statusView.Msg = fmt.Sprintf("export. %s", path)
+	})
+}
+
 func (p *Provider) open() {
 	obj := p.listView.GetCursorObject()
 	bucketName := p.bucket
 	switch obj.ObjType {
 	case model.Object:
+		if p.lister.IsLocal() {
+			path := model.LocalPath(bucketName, obj.Name)
+			if err := Open(path); err != nil {
+				log.Fatalf("failed open file, %v", err)
+			}
+			p.statusView.Msg = fmt.Sprintf("open. %s", path)
+			return
+		}
+
+		if !p.isOnline() {
+			return
+		}
 		tempDir, _ := ioutil.TempDir("", "")
-		f, err := os.Create(filepath.Join(tempDir, model.Filename(obj.Name)))
+		file, err := model.DownloadDecoded(bucketName, obj.Name, tempDir)
 		if err != nil {
-			log.Fatalf("failed create donwload reader, %v", err)
+			p.statusView.Msg = err.Error()
+			return
 		}
-		defer f.Close()
-
-		model.Download(bucketName, obj.Name, f)
-		if err := Open(f.Name()); err != nil {
+		if err := Open(file); err != nil {
 			log.Fatalf("failed open file, %v", err)
 		}
 
@@ -312,32 +882,98 @@ func (p *Provider) edit() {
 	bucketName := p.bucket
 	switch obj.ObjType {
 	case model.Object:
-		// download edit file on temporary file
-		tempDir, _ := ioutil.TempDir("", "")
-		f, err := os.Create(filepath.Join(tempDir, model.Filename(obj.Name)))
+		if p.lister.IsLocal() {
+			path := model.LocalPath(bucketName, obj.Name)
+			termbox.Close()
+			defer termbox.Init()
+			OpenEditor(path)
+			p.statusView.Msg = fmt.Sprintf("edit. %s", path)
+			return
+		}
+
+		if !p.isOnline() {
+			return
+		}
+		ef, err := model.DownloadForEdit(bucketName, obj.Name)
 		if err != nil {
-			log.Fatalf("failed create donwload reader, %v", err)
+			p.statusView.Msg = err.Error()
+			return
 		}
-		model.Download(bucketName, obj.Name, f)
-		editFilePath := f.Name()
-		f.Close()
+		p.editFile(ef)
+	default:
+		log.Println("Invalid s3 object type")
+	}
+}
 
-		// termbox close and restert for edit
-		termbox.Close()
-		defer termbox.Init()
-		OpenEditor(editFilePath)
+// editFile opens editor for edit file and saves it to the object.
+func (p *Provider) editFile(ef *model.EditFile) {
+	// termbox close and restert for edit
+	termbox.Close()
+	OpenEditor(ef.Path)
+	termbox.Init()
+	p.saveEdit(ef, false)
+}
+
+// saveEdit uploads edit file when it has been changed. Unless force, it asks how to
+// resolve when the object has been modified by someone else since download.
+func (p *Provider) saveEdit(ef *model.EditFile, force bool) {
+	path := "s3://" + strings.Join([]string{ef.Bucket, ef.Key}, "/")
+	changed, err := ef.Changed()
+	if err != nil {
+		p.statusView.Msg = err.Error()
+		return
+	}
+	if !changed {
+		ef.Close()
+		p.statusView.Msg = fmt.Sprintf("no changes. %s", path)
+		return
+	}
 
-		// update edited object
-		editedf, err := os.Open(editFilePath)
+	if !force {
+		conflict, err := ef.Conflict()
 		if err != nil {
-			log.Fatalf("failed open edited file, %v", err)
+			p.statusView.Msg = err.Error()
+			return
+		}
+		if conflict {
+			p.prompt("object was modified remotely. (o)verwrite, (a)bort or (m)erge: ", "", func(answer string) {
+				p.resolveEditConflict(ef, answer)
+			})
+			return
 		}
-		model.Update(bucketName, obj.Name, editedf)
+	}
 
-		path := "s3://" + strings.Join([]string{bucketName, obj.Name}, "/")
-		p.statusView.Msg = fmt.Sprintf("edit. %s", path)
+	if err := ef.Save(); err != nil {
+		p.statusView.Msg = err.Error()
+		return
+	}
+	p.invalidateS3Key(ef.Bucket, ef.Key)
+	ef.Close()
+	p.statusView.Msg = fmt.Sprintf("edit. %s", path)
+}
+
+func (p *Provider) resolveEditConflict(ef *model.EditFile, answer string) {
+	switch answer {
+	case "o", "overwrite":
+		p.saveEdit(ef, true)
+	case "m", "merge":
+		remotePath, err := ef.FetchRemote()
+		if err != nil {
+			p.statusView.Msg = err.Error()
+			return
+		}
+		if _, err := MergeFile(ef.Path, ef.BasePath, remotePath); err != nil {
+			p.statusView.Msg = fmt.Sprintf("%v, edited file is kept at %s", err, ef.Path)
+			return
+		}
+		if err := ef.Rebase(); err != nil {
+			p.statusView.Msg = err.Error()
+			return
+		}
+		// resolve conflict markers on editor
+		p.editFile(ef)
 	default:
-		log.Println("Invalid s3 object type")
+		p.statusView.Msg = fmt.Sprintf("edit aborted, edited file is kept at %s", ef.Path)
 	}
 }
 
@@ -350,7 +986,7 @@ func (p *Provider) show(obj *model.S3Object) {
 			p.moveNext(bucketName)
 			return
 		}
-		Objects := model.ListObjects(bucketName, "")
+		Objects := p.lister.ListObjects(bucketName, "")
 		p.loadNext(bucketName, Objects)
 	case model.Dir:
 		bucketName := p.bucket
@@ -359,7 +995,7 @@ func (p *Provider) show(obj *model.S3Object) {
 			p.moveNext(objectKey)
 			return
 		}
-		Objects := model.ListObjects(bucketName, objectKey)
+		Objects := p.lister.ListObjects(bucketName, objectKey)
 		p.loadNext(objectKey, Objects)
 	case model.PreDir:
 		p.loadPrev()
@@ -392,6 +1028,299 @@ func (p *Provider) loadPrev() {
 	log.Printf("Load prev. parent:%s", parent.Key)
 }
 
+// isS3Pane reports whether S3 only action is available on the active pane.
+func (p *Provider) isS3Pane() bool {
+	if p.lister.IsLocal() {
+		p.statusView.Msg = "not supported on local filesystem"
+		return false
+	}
+	return p.isOnline()
+}
+
+func (p *Provider) togglePreview() {
+	p.preview = !p.preview
+	p.previewKey = ""
+}
+
+func (p *Provider) toggleMiller() {
+	p.miller = !p.miller
+	p.previewKey = ""
+}
+
+// drawColumns draws parent listing and child listing or preview beside the current listing.
+func (p *Provider) drawColumns() {
+	if !p.node.IsRoot() {
+		p.parentView.Draw()
+	}
+	if len(p.listView.Objects) == 0 {
+		return
+	}
+	switch p.listView.GetCursorObject().ObjType {
+	case model.Bucket, model.Dir:
+		p.childView.Draw()
+	default:
+		p.previewView.Draw()
+	}
+}
+
+// requestPreview fetches preview of cursor object in background when cursor has moved.
+// On miller layout listing of cursor directory is loaded into node tree instead.
+func (p *Provider) requestPreview() {
+	if len(p.listView.Objects) == 0 {
+		return
+	}
+	obj := p.listView.GetCursorObject()
+	bucketName := p.bucket
+	id := strings.Join([]string{bucketName, obj.Name}, "/")
+	if id == p.previewKey {
+		return
+	}
+	p.previewKey = id
+	if p.lister.IsLocal() {
+		p.previewView.SetPreview(nil, errors.New("preview is not supported on local filesystem"))
+		return
+	}
+
+	if p.miller && (obj.ObjType == model.Bucket || obj.ObjType == model.Dir) {
+		p.requestChildList(obj)
+		return
+	}
+
+	p.previewView.SetPreview(nil, nil)
+
+	go func() {
+		preview, err := model.LoadPreview(bucketName, obj, model.PreviewSize)
+		p.post(func() {
+			if p.previewKey != id {
+				return
+			}
+			p.previewView.SetPreview(preview, err)
+		})
+	}()
+}
+
+func (p *Provider) view() {
+	obj := p.listView.GetCursorObject()
+	bucketName := p.bucket
+	switch obj.ObjType {
+	case model.Object:
+		if aws.Int64Value(obj.Size) > model.ViewerSize {
+			p.page()
+			return
+		}
+		p.status = StateViewer
+		p.viewerView.SetPreview(nil, nil)
+		go func() {
+			preview, err := model.LoadPreview(bucketName, obj, model.ViewerSize)
+			p.post(func() {
+				p.viewerView.SetPreview(preview, err)
+				p.statusView.Msg = p.viewerView.Title()
+			})
+		}()
+	default:
+		log.Println("Invalid s3 object type")
+	}
+}
+
+func (p *Provider) page() {
+	obj := p.listView.GetCursorObject()
+	bucketName := p.bucket
+	switch obj.ObjType {
+	case model.Object:
+		p.status = StatePager
+		p.pagerView.SetPager(obj.Name, nil, nil)
+		p.pagerReader = nil
+		go func() {
+			r, err := model.NewObjectReader(bucketName, obj.Name)
+			p.post(func() {
+				if err != nil {
+					p.pagerView.SetPager(obj.Name, nil, err)
+					return
+				}
+				p.pagerReader = r
+				p.pagerView.SetPager(obj.Name, model.NewPager(r), nil)
+				p.statusView.Msg = p.pagerView.Status()
+			})
+		}()
+	default:
+		log.Println("Invalid s3 object type")
+	}
+}
+
+func (p *Provider) closePager() {
+	p.stopFollow()
+	p.status = StateList
+}
+
+// searchPager searches query of pager from the next line, or the previous line when backward.
+func (p *Provider) searchPager(backward bool) {
+	pv := p.pagerView
+	if pv.Pager == nil || pv.Query == "" {
+		return
+	}
+	pager, query := pv.Pager, pv.Query
+	from := pv.Top
+	if !backward {
+		_, next, err := pager.ReadLine(pv.Top)
+		if err != nil {
+			p.statusView.Msg = err.Error()
+			return
+		}
+		from = next
+	}
+
+	p.statusView.Msg = fmt.Sprintf("searching %s ...", query)
+	go func() {
+		off, err := pager.Search(from, query, backward)
+		p.post(func() {
+			if pv.Pager != pager {
+				return
+			}
+			switch {
+			case err != nil:
+				p.statusView.Msg = err.Error()
+			case off < 0:
+				p.statusView.Msg = fmt.Sprintf("pattern not found: %s", query)
+			default:
+				pv.JumpTo(off)
+				p.statusView.Msg = pv.Status()
+			}
+		})
+	}()
+}
+
+func (p *Provider) jumpPagerLine(input string) {
+	pv := p.pagerView
+	line, err := strconv.ParseInt(input, 10, 64)
+	if err != nil || pv.Pager == nil {
+		p.statusView.Msg = fmt.Sprintf("invalid line number: %s", input)
+		return
+	}
+	pager := pv.Pager
+	p.statusView.Msg = fmt.Sprintf("scanning to line %d ...", line)
+	go func() {
+		off, err := pager.LineOffset(line)
+		p.post(func() {
+			if pv.Pager != pager {
+				return
+			}
+			if err != nil {
+				p.statusView.Msg = err.Error()
+				return
+			}
+			pv.Top = off
+			p.statusView.Msg = pv.Status()
+		})
+	}()
+}
+
+func (p *Provider) jumpPagerOffset(input string) {
+	off, err := strconv.ParseInt(input, 10, 64)
+	if err != nil || off < 0 {
+		p.statusView.Msg = fmt.Sprintf("invalid offset: %s", input)
+		return
+	}
+	p.pagerView.JumpTo(off)
+	p.statusView.Msg = p.pagerView.Status()
+}
+
+// toggleFollow polls the object and keeps showing its end when it is replaced by a grown one.
+func (p *Provider) toggleFollow() {
+	pv := p.pagerView
+	if pv.Follow {
+		p.stopFollow()
+		return
+	}
+	if p.pagerReader == nil {
+		return
+	}
+	pv.Follow = true
+	pv.Tail()
+
+	r, pager := p.pagerReader, pv.Pager
+	stop := make(chan struct{})
+	p.followStop = stop
+	go func() {
+		ticker := time.NewTicker(followInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				changed, err := r.Refresh()
+				p.post(func() {
+					if !pv.Follow || pv.Pager != pager {
+						return
+					}
+					if err != nil {
+						p.statusView.Msg = err.Error()
+						return
+					}
+					if changed {
+						pager.Reset()
+						pv.Tail()
+						p.statusView.Msg = pv.Status()
+					}
+				})
+			}
+		}
+	}()
+}
+
+func (p *Provider) stopFollow() {
+	if p.followStop != nil {
+		close(p.followStop)
+		p.followStop = nil
+	}
+	p.pagerView.Follow = false
+}
+
+// prompt opens text input on the status line and calls fn with the input on enter.
+func (p *Provider) prompt(label, text string, fn func(string)) {
+	p.promptStatus = p.status
+	p.status = StatePrompt
+	p.promptView.Reset(label, text)
+	p.promptFn = fn
+	p.promptComplete = nil
+}
+
+// requestChildList shows listing of cursor directory in the child column,
+// loading it into node tree in background so that moving into it is instant.
+func (p *Provider) requestChildList(obj *model.S3Object) {
+	node := p.node
+	key, bucketName, prefix := obj.Name, p.bucket, obj.Name
+	if obj.ObjType == model.Bucket {
+		bucketName, prefix = obj.Name, ""
+	}
+	if node.IsExistChildren(key) {
+		p.childView.UpdateList(node.GetChild(key))
+		return
+	}
+
+	p.childView.Objects = nil
+	p.childView.Err = nil
+	id := p.previewKey
+	lister := p.lister
+	go func() {
+		objects, err := model.FetchList(lister, bucketName, prefix)
+		p.post(func() {
+			if err != nil {
+				if p.previewKey == id {
+					p.childView.Err = err
+				}
+				return
+			}
+			if !node.IsExistChildren(key) {
+				node.AddChild(key, model.NewNode(key, node, objects))
+			}
+			if p.previewKey == id {
+				p.childView.UpdateList(node.GetChild(key))
+			}
+		})
+	}()
+}
+
 func (p *Provider) menu() {
 	p.status = StateMenu
 }
@@ -417,6 +1346,22 @@ func (p *Provider) Handle(ev termbox.Event) {
 		p.detailEvent(ev)
 	case StateDownload:
 		p.downloadEvent(ev)
+	case StateViewer:
+		p.viewerEvent(ev)
+	case StatePager:
+		p.pagerEvent(ev)
+	case StatePrompt:
+		p.promptEvent(ev)
+	case StateReview:
+		p.reviewEvent(ev)
+	case StateBookmark:
+		p.bookmarkEvent(ev)
+	case StateHistory:
+		p.historyEvent(ev)
+	case StateUsage:
+		p.usageEvent(ev)
+	case StateSearch:
+		p.searchEvent(ev)
 	}
 }
 
@@ -429,6 +1374,7 @@ func (p *Provider) listEvent(ev termbox.Event) {
 
 	switch ea {
 	case actQuit:
+		p.quit = true
 		go func() {
 			termbox.Interrupt()
 			time.Sleep(1 * time.Second)
@@ -446,7 +1392,11 @@ func (p *Provider) listEvent(ev termbox.Event) {
 		p.menu()
 	case actOpenDetail:
 		obj := p.listView.GetCursorObject()
-		p.detail(obj)
+		if obj.ObjType == model.Dir || obj.ObjType == model.Bucket {
+			p.showUsage(obj)
+		} else if p.isS3Pane() {
+			p.detail(obj)
+		}
 	case actOpenDownload:
 		p.openDownload()
 	case actMovePrevDir:
@@ -461,9 +1411,86 @@ func (p *Provider) listEvent(ev termbox.Event) {
 	case actOpenObject:
 		p.open()
 	case actDownloadObject:
-		p.download()
+		if p.isS3Pane() {
+			p.download()
+		}
 	case actEditObject:
 		p.edit()
+	case actViewObject:
+		if p.isS3Pane() {
+			p.view()
+		}
+	case actPageObject:
+		if p.isS3Pane() {
+			p.page()
+		}
+	case actTogglePreview:
+		p.togglePreview()
+	case actToggleMiller:
+		p.toggleMiller()
+	case actToggleCommander:
+		p.toggleCommander()
+	case actSwitchPane:
+		p.switchPane()
+	case actCopyToPane:
+		p.transferToPane(false)
+	case actMoveToPane:
+		p.transferToPane(true)
+	case actToggleLocal:
+		p.toggleLocal()
+	case actSwitchProfile:
+		p.prompt("profile: ", p.profile, p.switchProfile)
+	case actNewFile:
+		p.newFile()
+	case actMakeDir:
+		p.makeDir()
+	case actEditProperties:
+		if p.isS3Pane() {
+			p.editProperties()
+		}
+	case actBucketConfig:
+		if p.isS3Pane() {
+			p.editBucketConfig()
+		}
+	case actExportListing:
+		p.exportListing()
+	case actGoTo:
+		p.goTo()
+	case actAddBookmark:
+		p.addBookmark()
+	case actOpenBookmark:
+		p.openBookmarks()
+	case actBack:
+		p.back()
+	case actForward:
+		p.forward()
+	case actOpenHistory:
+		p.openHistory()
+	case actCalculateUsage:
+		p.calculateUsage()
+	case actExploreUsage:
+		p.exploreUsage()
+	case actEstimateCost:
+		p.estimateCost()
+	case actSearchKeys:
+		p.searchKeys()
+	case actOpenSearch:
+		p.openSearch()
+	case actNewTab:
+		p.newTab()
+	case actCloseTab:
+		p.closeTab()
+	case actNextTab:
+		p.switchTab(p.tab + 1)
+	case actPrevTab:
+		p.switchTab(p.tab - 1)
+	case actToggleMark:
+		p.listView.ToggleMark()
+		p.node.Position = p.listView.Down()
+	case actScrollPreviewUp:
+		p.previewView.Up()
+	case actScrollPreviewDown:
+		p.previewView.Down()
 	default:
 	}
 }
@@ -484,15 +1511,29 @@ func (p *Provider) menuEvent(ev termbox.Event) {
 		p.menuView.Down()
 	case actDoMenuAction:
 		item := p.menuView.GetCursorItem()
+		p.status = StateList
 		switch item.Command {
 		case view.CommandDownload:
-			p.download()
+			if p.isS3Pane() {
+				p.download()
+			}
 		case view.CommandOpen:
 			p.open()
 		case view.CommandEdit:
 			p.edit()
+		case view.CommandView:
+			if p.isS3Pane() {
+				p.view()
+			}
+		case view.CommandNewFile:
+			p.newFile()
+		case view.CommandMakeDir:
+			p.makeDir()
+		case view.CommandProperties:
+			if p.isS3Pane() {
+				p.editProperties()
+			}
 		}
-		p.status = StateList
 	default:
 	}
 }
@@ -540,3 +1581,117 @@ func (p *Provider) downloadEvent(ev termbox.Event) {
 	default:
 	}
 }
+
+func (p *Provider) viewerEvent(ev termbox.Event) {
+	ea := getEventAction(ev, chMapOnViewer, keyMapOnViewer)
+	if ea == "" {
+		p.statusView.Msg = "no mapping key"
+		return
+	}
+
+	switch ea {
+	case actQuit:
+		p.status = StateList
+	case actUp:
+		p.viewerView.Up()
+	case actDown:
+		p.viewerView.Down()
+	case actHalfUp:
+		p.viewerView.HalfPageUp()
+	case actHalfDown:
+		p.viewerView.HalfPageDown()
+	case actLeft:
+		p.viewerView.Left()
+	case actRight:
+		p.viewerView.Right()
+	case actToggleFold:
+		p.viewerView.ToggleFold()
+	default:
+	}
+}
+
+func (p *Provider) pagerEvent(ev termbox.Event) {
+	ea := getEventAction(ev, chMapOnPager, keyMapOnPager)
+	if ea == "" {
+		p.statusView.Msg = "no mapping key"
+		return
+	}
+
+	pv := p.pagerView
+	_, height := termbox.Size()
+	switch ea {
+	case actQuit:
+		p.closePager()
+		return
+	case actUp:
+		pv.Up(1)
+	case actDown:
+		pv.Down(1)
+	case actHalfUp:
+		pv.HalfPageUp()
+	case actHalfDown:
+		pv.HalfPageDown()
+	case actPageUp:
+		pv.Up(height - 3)
+	case actPageDown:
+		pv.Down(height - 3)
+	case actTop:
+		pv.Head()
+	case actBottom:
+		pv.Tail()
+	case actLeft:
+		pv.Left()
+	case actRight:
+		pv.Right()
+	case actSearch, actSearchBackward:
+		backward := ea == actSearchBackward
+		label := "/"
+		if backward {
+			label = "?"
+		}
+		p.prompt(label, "", func(query string) {
+			pv.Query = query
+			pv.Backward = backward
+			p.searchPager(backward)
+		})
+		return
+	case actSearchNext:
+		p.searchPager(pv.Backward)
+		return
+	case actSearchPrev:
+		p.searchPager(!pv.Backward)
+		return
+	case actJumpLine:
+		p.prompt("line: ", "", p.jumpPagerLine)
+		return
+	case actJumpOffset:
+		p.prompt("offset: ", "", p.jumpPagerOffset)
+		return
+	case actToggleFollow:
+		p.toggleFollow()
+	case actToggleWrap:
+		pv.Wrap = !pv.Wrap
+	default:
+	}
+	p.statusView.Msg = pv.Status()
+}
+
+func (p *Provider) promptEvent(ev termbox.Event) {
+	switch ev.Key {
+	case termbox.KeyEsc, termbox.KeyCtrlC:
+		p.status = p.promptStatus
+		termbox.HideCursor()
+	case termbox.KeyEnter:
+		p.status = p.promptStatus
+		termbox.HideCursor()
+		p.promptFn(p.promptView.Value())
+	case termbox.KeyTab:
+		if p.promptComplete != nil {
+			value, candidates := p.promptComplete(p.promptView.Value())
+			p.promptView.SetValue(value)
+			p.promptView.Candidates = candidates
+		}
+	default:
+		p.promptView.Input(ev)
+	}
+}
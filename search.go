@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lighttiger2505/s3tf/model"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// keySearch is recursive search by key pattern shown on search view.
+type keySearch struct {
+	loc     *model.Location
+	pattern string
+	stop    chan struct{}
+	done    bool
+	scanned int64
+}
+
+func (s *keySearch) stopped() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// searchLocation returns bucket on the cursor of bucket list, or the current directory.
+func (p *Provider) searchLocation() *model.Location {
+	if p.node.IsRoot() {
+		return p.usageLocation(p.listView.GetCursorObject())
+	}
+	return p.Location()
+}
+
+// searchKeys asks pattern and lists all keys under the current directory matching it.
+func (p *Provider) searchKeys() {
+	loc := p.searchLocation()
+	if loc == nil {
+		p.statusView.Msg = "select bucket to search"
+		return
+	}
+	if !loc.Local && !p.isOnline() {
+		return
+	}
+	pattern := ""
+	if p.search != nil {
+		pattern = p.search.pattern
+	}
+	p.prompt(fmt.Sprintf("search in %s (substring, glob or /regexp/): ", loc), pattern, func(pattern string) {
+		match, err := model.NewMatcher(pattern)
+		if err != nil {
+			p.statusView.Msg = err.Error()
+			return
+		}
+		p.startSearch(loc, pattern, match)
+	})
+}
+
+func (p *Provider) startSearch(loc *model.Location, pattern string, match model.Matcher) {
+	p.stopSearch()
+	s := &keySearch{loc: loc, pattern: pattern, stop: make(chan struct{})}
+	p.search = s
+	p.searchView.Reset()
+	p.status = StateSearch
+	p.statusView.Msg = p.searchTitle()
+	go func() {
+		found := func(matches []*model.S3Object, scanned int64) bool {
+			p.post(func() {
+				if p.search != s {
+					return
+				}
+				s.scanned = scanned
+				p.searchView.Append(matches)
+				if p.status == StateSearch {
+					p.statusView.Msg = p.searchTitle()
+				}
+			})
+			return !s.stopped()
+		}
+		var err error
+		if loc.Local {
+			err = model.SearchLocalFiles(loc.Bucket, loc.Key, match, found)
+		} else {
+			err = model.SearchObjects(loc.Profile, loc.Bucket, loc.Key, match, found)
+		}
+		p.post(func() {
+			if p.search != s {
+				return
+			}
+			s.done = true
+			if err != nil {
+				p.statusView.Msg = fmt.Sprintf("failed search %s, %v", loc, err)
+				return
+			}
+			if p.status == StateSearch {
+				p.statusView.Msg = p.searchTitle()
+			}
+		})
+	}()
+}
+
+// stopSearch stops listing of the running search.
+func (p *Provider) stopSearch() {
+	if s := p.search; s != nil && !s.done && !s.stopped() {
+		close(s.stop)
+	}
+}
+
+func (p *Provider) searchTitle() string {
+	s := p.search
+	state := "searching"
+	if s.done {
+		state = "search"
+	} else if s.stopped() {
+		state = "stopped"
+	}
+	return fmt.Sprintf("%s. %q in %s, %d found in %d objects", state, s.pattern, s.loc, len(p.searchView.Objects), s.scanned)
+}
+
+// openSearch shows results of the last search again.
+func (p *Provider) openSearch() {
+	if p.search == nil {
+		p.statusView.Msg = "no search results. press / to search"
+		return
+	}
+	p.status = StateSearch
+	p.statusView.Msg = p.searchTitle()
+}
+
+// jumpSearch opens directory containing the match on the cursor with cursor on it.
+// The search goes on to come back by openSearch.
+func (p *Provider) jumpSearch() {
+	obj := p.searchView.GetCursorObject()
+	if obj == nil {
+		return
+	}
+	loc := *p.search.loc
+	loc.Key = obj.Name
+	p.status = StateList
+	p.pushNavigation()
+	p.showLocation(&loc)
+}
+
+// downloadMatches queues download of all matches to the current directory keeping their tree.
+func (p *Provider) downloadMatches() {
+	s := p.search
+	matches := p.searchView.Objects
+	if len(matches) == 0 {
+		p.statusView.Msg = "no matches to download"
+		return
+	}
+	currentDir, _ := os.Getwd()
+	bucket, key := model.LocalKey(currentDir)
+	p.prompt(fmt.Sprintf("download %d matches to %s? (y/n): ", len(matches), currentDir), "", func(answer string) {
+		if answer != "y" {
+			p.statusView.Msg = "abort download"
+			return
+		}
+		for _, obj := range matches {
+			src := &model.Endpoint{Local: s.loc.Local, Profile: s.loc.Profile, Bucket: s.loc.Bucket, Key: obj.Name}
+			dst := &model.Endpoint{Local: true, Bucket: bucket, Key: key + strings.TrimPrefix(obj.Name, s.loc.Key)}
+			p.transferQueue.Add(model.NewTransfer(src, dst, false, false))
+		}
+		p.statusView.Msg = fmt.Sprintf("queued download of %d matches", len(matches))
+	})
+}
+
+func (p *Provider) searchEvent(ev termbox.Event) {
+	ea := getEventAction(ev, chMapOnSearch, keyMapOnSearch)
+	if ea == "" {
+		p.statusView.Msg = "no mapping key"
+		return
+	}
+
+	switch ea {
+	case actQuit:
+		p.status = StateList
+		return
+	case actStopSearch:
+		p.stopSearch()
+	case actUp:
+		p.searchView.Up()
+	case actDown:
+		p.searchView.Down()
+	case actHalfUp:
+		p.searchView.HalfPageUp()
+	case actHalfDown:
+		p.searchView.HalfPageDown()
+	case actJumpSearch:
+		p.jumpSearch()
+		return
+	case actDownloadMatches:
+		p.downloadMatches()
+		return
+	case actSearchKeys:
+		p.status = StateList
+		p.searchKeys()
+		return
+	default:
+	}
+	p.statusView.Msg = p.searchTitle()
+}
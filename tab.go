@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/lighttiger2505/s3tf/model"
+	"github.com/lighttiger2505/s3tf/view"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// title is short name of the pane shown on tab bar.
+func (pn *pane) title() string {
+	var name string
+	switch {
+	case pn.node.IsRoot() && pn.lister.IsLocal():
+		name = "local"
+	case pn.node.IsRoot():
+		name = "s3://"
+	case pn.node.Key != "":
+		name = path.Base(pn.node.Key)
+	default:
+		name = pn.bucket
+	}
+	if pn.profile != "" && !pn.lister.IsLocal() {
+		name = pn.profile + ":" + name
+	}
+	return name
+}
+
+// newTab opens a tab on the current location with its own node tree and cursor.
+func (p *Provider) newTab() {
+	p.tabs[p.tab] = p.activePane()
+	width, height := termbox.Size()
+	key := ""
+	if !p.node.IsRoot() {
+		key = p.node.Prefix()
+	}
+	pn := &pane{
+		lister:         p.lister,
+		profile:        p.profile,
+		node:           model.NewNodeTree(p.lister, p.bucket, key),
+		bucket:         p.bucket,
+		listView:       view.NewListView(0, 1, width, height-2),
+		navigationView: view.NewNavigationView(0, 0, width, 1),
+	}
+	pn.listView.UpdateList(pn.node)
+	p.tabs = append(p.tabs, pn)
+	p.tab = len(p.tabs) - 1
+	p.restorePane(pn)
+	p.statusView.Msg = fmt.Sprintf("new tab. %d", p.tab+1)
+}
+
+func (p *Provider) closeTab() {
+	if len(p.tabs) == 1 {
+		p.statusView.Msg = "last tab can not be closed"
+		return
+	}
+	p.tabs = append(p.tabs[:p.tab], p.tabs[p.tab+1:]...)
+	if p.tab == len(p.tabs) {
+		p.tab--
+	}
+	p.restorePane(p.tabs[p.tab])
+	p.statusView.Msg = fmt.Sprintf("close tab. %d tabs", len(p.tabs))
+}
+
+// switchTab activates i th tab, wrapping around.
+func (p *Provider) switchTab(i int) {
+	if len(p.tabs) == 1 {
+		return
+	}
+	p.tabs[p.tab] = p.activePane()
+	p.tab = (i + len(p.tabs)) % len(p.tabs)
+	p.restorePane(p.tabs[p.tab])
+}
+
+// updateTabView refreshes the active tab in Provider.tabs from the location fields and sets tab bar.
+func (p *Provider) updateTabView() {
+	p.tabs[p.tab] = p.activePane()
+	names := make([]string, len(p.tabs))
+	for i, pn := range p.tabs {
+		names[i] = pn.title()
+	}
+	p.tabView.Names = names
+	p.tabView.Active = p.tab
+}
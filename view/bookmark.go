@@ -0,0 +1,91 @@
+package view
+
+import (
+	"github.com/lighttiger2505/s3tf/model"
+	termbox "github.com/nsf/termbox-go"
+)
+
+type BookmarkView struct {
+	Render
+	Layer   *Layer
+	Objects []*model.Bookmark
+}
+
+func NewBookmarkView(x, y, width, height int) *BookmarkView {
+	return &BookmarkView{
+		Layer: NewLayer(x, y, width, height),
+	}
+}
+
+func (v *BookmarkView) getContents() []string {
+	width := 0
+	for _, b := range v.Objects {
+		if len(b.Name) > width {
+			width = len(b.Name)
+		}
+	}
+	drawLines := []string{}
+	for _, b := range v.Objects {
+		line := PadRight(b.Name, width, " ") + " " + b.Location.String()
+		if b.Profile != "" && !b.Local {
+			line += " (" + b.Profile + ")"
+		}
+		drawLines = append(drawLines, line)
+	}
+	return drawLines
+}
+
+// GetCursorObject returns bookmark on the cursor, nil when there is none.
+func (v *BookmarkView) GetCursorObject() *model.Bookmark {
+	if len(v.Objects) == 0 {
+		return nil
+	}
+	return v.Objects[v.Layer.cursorPos.Y]
+}
+
+// Update sets bookmarks keeping the cursor in them.
+func (v *BookmarkView) Update(objects []*model.Bookmark) {
+	v.Objects = objects
+	if v.Layer.cursorPos.Y >= len(objects) {
+		v.Layer.cursorPos.Y = len(objects) - 1
+	}
+	if v.Layer.cursorPos.Y < 0 {
+		v.Layer.cursorPos.Y = 0
+	}
+	v.Layer.keepCursorVisible()
+}
+
+func (v *BookmarkView) Up() int {
+	return v.Layer.UpCursor(1)
+}
+
+func (v *BookmarkView) Down() int {
+	if len(v.Objects) == 0 {
+		return 0
+	}
+	return v.Layer.DownCursor(1, len(v.Objects))
+}
+
+func (v *BookmarkView) HalfPageUp() int {
+	return v.Layer.HalfPageUpCursor()
+}
+
+func (v *BookmarkView) HalfPageDown() int {
+	if len(v.Objects) == 0 {
+		return 0
+	}
+	return v.Layer.HalfPageDownCursor(len(v.Objects))
+}
+
+func (v *BookmarkView) Draw() {
+	v.Layer.DrawBackGround(termbox.ColorDefault, termbox.ColorDefault)
+
+	lines := v.getContents()
+	v.Layer.DrawContents(
+		lines,
+		termbox.ColorWhite,
+		termbox.ColorGreen,
+		termbox.ColorDefault,
+		termbox.ColorDefault,
+	)
+}
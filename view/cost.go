@@ -0,0 +1,60 @@
+package view
+
+import (
+	"fmt"
+
+	"github.com/lighttiger2505/s3tf/model"
+)
+
+// RenderCost renders estimated monthly storage cost and projections of changing storage class.
+func RenderCost(path string, usage *model.DirUsage, est *model.CostEstimate) *Document {
+	money := func(v float64) string {
+		return fmt.Sprintf("%.2f %s", v, est.Currency)
+	}
+	doc := &Document{}
+	doc.Lines = append(doc.Lines,
+		newLine(Span{Text: path, FG: colorHeader}),
+		plainLine(""),
+		plainLine(fmt.Sprintf("    Region: %s", est.Region)),
+		plainLine(fmt.Sprintf("    Total: %s, %d objects", model.FormatBytes(usage.Bytes), usage.Count)),
+		plainLine(fmt.Sprintf("    Monthly: %s", money(est.Monthly))),
+		plainLine(""),
+		newLine(Span{Text: fmt.Sprintf("    %-20s %12s %12s %10s %16s", "StorageClass", "Size", "Billable", "Objects", "Monthly"), FG: colorComment}),
+	)
+	for _, l := range est.Lines {
+		monthly := money(l.Monthly)
+		if l.Unknown {
+			monthly = "no price"
+		}
+		doc.Lines = append(doc.Lines, plainLine(fmt.Sprintf("    %-20s %12s %12s %10d %16s",
+			l.Class, model.FormatBytes(l.Bytes), model.FormatBytes(l.BillableBytes), l.Count, monthly)))
+	}
+
+	doc.Lines = append(doc.Lines,
+		plainLine(""),
+		newLine(Span{Text: "    Change storage class of all objects to", FG: colorHeader}),
+		newLine(Span{Text: fmt.Sprintf("    %-20s %16s %16s %16s %20s", "StorageClass", "Monthly", "Savings", "Transition", "Minimum charge"), FG: colorComment}),
+	)
+	for _, c := range est.Changes {
+		minimum := "-"
+		if c.MinDays > 0 {
+			minimum = fmt.Sprintf("%s/%dd", money(c.MinimumCharge), c.MinDays)
+		}
+		doc.Lines = append(doc.Lines, plainLine(fmt.Sprintf("    %-20s %16s %16s %16s %20s",
+			c.Class, money(c.Monthly), money(c.Savings), money(c.Transition), minimum)))
+	}
+	for _, l := range est.Lines {
+		if l.Unknown {
+			doc.Lines = append(doc.Lines,
+				plainLine(""),
+				newLine(Span{Text: "    Storage classes without price are not included in the total and the projections.", FG: colorComment}),
+			)
+			break
+		}
+	}
+	doc.Lines = append(doc.Lines,
+		plainLine(""),
+		newLine(Span{Text: "    Prices are read from prices.yml in the config directory. Requests and retrievals are not included.", FG: colorComment}),
+	)
+	return doc
+}
@@ -0,0 +1,44 @@
+package view
+
+import (
+	"regexp"
+	"strings"
+
+	termbox "github.com/nsf/termbox-go"
+)
+
+const (
+	colorAdded   = termbox.ColorGreen
+	colorRemoved = termbox.ColorRed
+	colorWarning = termbox.ColorRed | termbox.AttrBold
+	colorPublic  = termbox.ColorRed | termbox.AttrBold | termbox.AttrReverse
+)
+
+// publicPrincipal matches lines of policy granting to anyone, e.g. "Principal": "*" or "AWS": "*".
+var publicPrincipal = regexp.MustCompile(`"(Principal|AWS)"\s*:\s*(\[\s*)?"\*"`)
+
+// RenderDiff renders unified diff, preceded by warning lines.
+func RenderDiff(diff string, warnings []string) *Document {
+	doc := &Document{}
+	for _, w := range warnings {
+		doc.Lines = append(doc.Lines, newLine(Span{Text: "WARNING: " + w, FG: colorWarning}))
+	}
+	for _, text := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		fg := termbox.ColorDefault
+		switch {
+		case strings.HasPrefix(text, "+++"), strings.HasPrefix(text, "---"):
+			fg = colorHeader
+		case strings.HasPrefix(text, "@@"):
+			fg = colorComment
+		case strings.HasPrefix(text, "+"):
+			fg = colorAdded
+		case strings.HasPrefix(text, "-"):
+			fg = colorRemoved
+		}
+		if publicPrincipal.MatchString(text) && !strings.HasPrefix(text, "-") {
+			fg = colorPublic
+		}
+		doc.Lines = append(doc.Lines, newLine(Span{Text: strings.Replace(text, "\t", "    ", -1), FG: fg}))
+	}
+	return doc
+}
@@ -0,0 +1,415 @@
+package view
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/lighttiger2505/s3tf/model"
+	runewidth "github.com/mattn/go-runewidth"
+	termbox "github.com/nsf/termbox-go"
+)
+
+const (
+	colorKey     = termbox.ColorCyan
+	colorString  = termbox.ColorGreen
+	colorNumber  = termbox.ColorMagenta
+	colorKeyword = termbox.ColorYellow
+	colorComment = termbox.ColorBlue
+	colorHeader  = termbox.ColorCyan | termbox.AttrBold
+	colorLineNum = termbox.ColorBlack | termbox.AttrBold
+
+	maxColumnWidth = 40
+)
+
+type Span struct {
+	Text string
+	FG   termbox.Attribute
+}
+
+type Line struct {
+	Spans []Span
+	// FoldEnd is index of the last line in block opened by this line, -1 when line opens no block.
+	FoldEnd int
+}
+
+func newLine(spans ...Span) *Line {
+	return &Line{Spans: spans, FoldEnd: -1}
+}
+
+func plainLine(text string) *Line {
+	return newLine(Span{Text: text, FG: termbox.ColorDefault})
+}
+
+func (l *Line) String() string {
+	var b strings.Builder
+	for _, span := range l.Spans {
+		b.WriteString(span.Text)
+	}
+	return b.String()
+}
+
+// Document is lines of object contents rendered for a format.
+type Document struct {
+	Lines []*Line
+	// Sticky is the number of leading lines pinned on top when scrolling, e.g. table header.
+	Sticky int
+}
+
+// RenderPreview renders contents of preview by detected format.
+func RenderPreview(pv *model.Preview) *Document {
+	if pv.Binary {
+		return renderHexDump(pv.Body)
+	}
+	switch pv.Format {
+	case model.FormatJSON:
+		return renderJSON(pv.Body)
+	case model.FormatNDJSON:
+		return renderNDJSON(pv.Body)
+	case model.FormatYAML:
+		return renderYAML(pv.Body)
+	case model.FormatCSV:
+		return renderTable(pv.Body, ',')
+	case model.FormatTSV:
+		return renderTable(pv.Body, '\t')
+	case model.FormatSource:
+		return renderSource(pv.Body, model.Language(pv.Key))
+	}
+	return renderText(pv.Body)
+}
+
+func splitLines(body []byte) []string {
+	text := strings.Replace(string(body), "\t", "    ", -1)
+	return strings.Split(strings.TrimRight(text, "\n"), "\n")
+}
+
+func renderText(body []byte) *Document {
+	doc := &Document{}
+	for i, line := range splitLines(body) {
+		doc.Lines = append(doc.Lines, numberedLine(i+1, plainLine(line)))
+	}
+	return doc
+}
+
+func numberedLine(num int, line *Line) *Line {
+	line.Spans = append([]Span{{Text: fmt.Sprintf("%4d ", num), FG: colorLineNum}}, line.Spans...)
+	return line
+}
+
+func renderHexDump(body []byte) *Document {
+	doc := &Document{}
+	for _, line := range strings.Split(strings.TrimRight(hex.Dump(body), "\n"), "\n") {
+		doc.Lines = append(doc.Lines, plainLine(line))
+	}
+	return doc
+}
+
+// indentJSON pretty-prints JSON keeping key order. Unlike json.Indent it
+// accepts a truncated document, as previews only hold the head of object.
+func indentJSON(body []byte) string {
+	var b strings.Builder
+	depth := 0
+	inString, escaped, emptyBlock := false, false, false
+	newline := func() {
+		b.WriteString("\n")
+		b.WriteString(strings.Repeat("  ", depth))
+	}
+	nextByte := func(i int) byte {
+		for j := i + 1; j < len(body); j++ {
+			if !unicode.IsSpace(rune(body[j])) {
+				return body[j]
+			}
+		}
+		return 0
+	}
+
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if inString {
+			b.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case ' ', '\t', '\r', '\n':
+		case '"':
+			inString = true
+			b.WriteByte(c)
+		case '{', '[':
+			b.WriteByte(c)
+			if next := nextByte(i); next == '}' || next == ']' {
+				emptyBlock = true
+				continue
+			}
+			depth++
+			newline()
+		case '}', ']':
+			if !emptyBlock {
+				depth--
+				newline()
+			}
+			emptyBlock = false
+			b.WriteByte(c)
+		case ',':
+			b.WriteByte(c)
+			newline()
+		case ':':
+			b.WriteString(": ")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func renderJSON(body []byte) *Document {
+	doc := &Document{}
+	for _, text := range strings.Split(indentJSON(body), "\n") {
+		doc.Lines = append(doc.Lines, highlightJSON(text))
+	}
+	setBracketFolds(doc.Lines)
+	return doc
+}
+
+func renderNDJSON(body []byte) *Document {
+	doc := &Document{}
+	for _, record := range bytes.Split(body, []byte("\n")) {
+		if len(bytes.TrimSpace(record)) == 0 {
+			continue
+		}
+		lines := []*Line{}
+		for _, text := range strings.Split(indentJSON(record), "\n") {
+			lines = append(lines, highlightJSON(text))
+		}
+		setBracketFolds(lines)
+		for _, line := range lines {
+			if line.FoldEnd >= 0 {
+				line.FoldEnd += len(doc.Lines)
+			}
+		}
+		doc.Lines = append(doc.Lines, lines...)
+	}
+	return doc
+}
+
+func highlightJSON(text string) *Line {
+	line := newLine()
+	rest := text
+	for len(rest) > 0 {
+		c := rest[0]
+		switch {
+		case c == '"':
+			end := closingQuote(rest)
+			fg := colorString
+			if strings.HasPrefix(rest[end:], ":") {
+				fg = colorKey
+			}
+			line.Spans = append(line.Spans, Span{Text: rest[:end], FG: fg})
+			rest = rest[end:]
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := strings.IndexAny(rest, ",]} ")
+			if end < 0 {
+				end = len(rest)
+			}
+			line.Spans = append(line.Spans, Span{Text: rest[:end], FG: colorNumber})
+			rest = rest[end:]
+		case strings.HasPrefix(rest, "true") || strings.HasPrefix(rest, "false") || strings.HasPrefix(rest, "null"):
+			end := strings.IndexAny(rest, ",]} ")
+			if end < 0 {
+				end = len(rest)
+			}
+			line.Spans = append(line.Spans, Span{Text: rest[:end], FG: colorKeyword})
+			rest = rest[end:]
+		default:
+			_, size := utf8.DecodeRuneInString(rest)
+			line.Spans = append(line.Spans, Span{Text: rest[:size], FG: termbox.ColorDefault})
+			rest = rest[size:]
+		}
+	}
+	return line
+}
+
+// closingQuote returns index just after the string literal at head of s.
+func closingQuote(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// setBracketFolds sets fold range of lines opening a JSON object or array.
+func setBracketFolds(lines []*Line) {
+	stack := []int{}
+	for i, line := range lines {
+		text := strings.TrimSpace(line.String())
+		if strings.HasPrefix(text, "}") || strings.HasPrefix(text, "]") {
+			if len(stack) > 0 {
+				lines[stack[len(stack)-1]].FoldEnd = i
+				stack = stack[:len(stack)-1]
+			}
+		}
+		if strings.HasSuffix(text, "{") || strings.HasSuffix(text, "[") {
+			stack = append(stack, i)
+		}
+	}
+	// blocks cut off by truncation fold to the end
+	for _, i := range stack {
+		lines[i].FoldEnd = len(lines) - 1
+	}
+}
+
+// renderYAML highlights YAML as it is, since round trip through a YAML
+// decoder drops comments and rewrites scalars like "y" to booleans.
+// Flow style documents are expanded like JSON.
+func renderYAML(body []byte) *Document {
+	if trimmed := bytes.TrimSpace(body); bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("[")) {
+		return renderJSON(body)
+	}
+
+	doc := &Document{}
+	for _, text := range splitLines(body) {
+		doc.Lines = append(doc.Lines, highlightYAML(text))
+	}
+	setIndentFolds(doc.Lines)
+	return doc
+}
+
+func highlightYAML(text string) *Line {
+	line := newLine()
+	trimmed := strings.TrimLeft(text, " ")
+	line.Spans = append(line.Spans, Span{Text: text[:len(text)-len(trimmed)]})
+	if strings.HasPrefix(trimmed, "#") {
+		line.Spans = append(line.Spans, Span{Text: trimmed, FG: colorComment})
+		return line
+	}
+	for strings.HasPrefix(trimmed, "- ") {
+		line.Spans = append(line.Spans, Span{Text: "- ", FG: colorKeyword})
+		trimmed = trimmed[2:]
+	}
+	value := trimmed
+	if i := strings.Index(trimmed, ": "); i > 0 && !strings.ContainsAny(trimmed[:i], "\"'") {
+		line.Spans = append(line.Spans, Span{Text: trimmed[:i+1], FG: colorKey}, Span{Text: " "})
+		value = trimmed[i+2:]
+	} else if strings.HasSuffix(trimmed, ":") {
+		line.Spans = append(line.Spans, Span{Text: trimmed, FG: colorKey})
+		return line
+	}
+	line.Spans = append(line.Spans, Span{Text: value, FG: scalarColor(value)})
+	return line
+}
+
+func scalarColor(value string) termbox.Attribute {
+	switch value {
+	case "true", "false", "null", "~", "yes", "no":
+		return colorKeyword
+	}
+	if _, err := fmt.Sscanf(value, "%g", new(float64)); err == nil {
+		return colorNumber
+	}
+	return colorString
+}
+
+// setIndentFolds sets fold range of lines followed by more indented lines.
+func setIndentFolds(lines []*Line) {
+	indents := make([]int, len(lines))
+	for i, line := range lines {
+		text := line.String()
+		indents[i] = len(text) - len(strings.TrimLeft(text, " "))
+		// sequence items of a mapping key may be on the same indent as the key
+		if strings.HasPrefix(strings.TrimLeft(text, " "), "- ") {
+			indents[i]++
+		}
+	}
+	for i := range lines {
+		end := i
+		for j := i + 1; j < len(lines) && indents[j] > indents[i]; j++ {
+			end = j
+		}
+		if end > i {
+			lines[i].FoldEnd = end
+		}
+	}
+}
+
+func renderTable(body []byte, comma rune) *Document {
+	r := csv.NewReader(bytes.NewReader(body))
+	r.Comma = comma
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+
+	records := [][]string{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// the last record of a truncated preview may be broken
+			break
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return renderText(body)
+	}
+
+	widths := []int{}
+	for _, record := range records {
+		for i, field := range record {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			w := runewidth.StringWidth(field)
+			if w > maxColumnWidth {
+				w = maxColumnWidth
+			}
+			if w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	doc := &Document{Sticky: 2}
+	for i, record := range records {
+		cells := make([]string, len(widths))
+		for j := range widths {
+			field := ""
+			if j < len(record) {
+				field = record[j]
+			}
+			cells[j] = runewidth.FillRight(runewidth.Truncate(field, widths[j], "~"), widths[j])
+		}
+		fg := termbox.ColorDefault
+		if i == 0 {
+			fg = colorHeader
+		}
+		doc.Lines = append(doc.Lines, newLine(Span{Text: strings.Join(cells, " | "), FG: fg}))
+		if i == 0 {
+			seps := make([]string, len(widths))
+			for j, w := range widths {
+				seps[j] = strings.Repeat("-", w)
+			}
+			doc.Lines = append(doc.Lines, newLine(Span{Text: strings.Join(seps, "-+-"), FG: colorHeader}))
+		}
+	}
+	return doc
+}
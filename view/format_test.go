@@ -0,0 +1,65 @@
+package view
+
+import (
+	"testing"
+)
+
+func TestIndentJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{
+			`{"b":1,"a":[true,null],"c":{}}`,
+			"{\n  \"b\": 1,\n  \"a\": [\n    true,\n    null\n  ],\n  \"c\": {}\n}",
+		},
+		{
+			`{"s":"a,{b}:\"c"}`,
+			"{\n  \"s\": \"a,{b}:\\\"c\"\n}",
+		},
+		{
+			`{"truncated":[1,2`,
+			"{\n  \"truncated\": [\n    1,\n    2",
+		},
+	}
+	for _, tt := range tests {
+		got := indentJSON([]byte(tt.in))
+		if got != tt.want {
+			t.Errorf("indentJSON(%s) want\n%s\nbut\n%s", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestRenderJSONFolds(t *testing.T) {
+	doc := renderJSON([]byte(`{"a":{"b":1},"c":[1`))
+	want := []int{5, 3, -1, -1, 5, -1}
+	if len(doc.Lines) != len(want) {
+		t.Fatalf("want %d lines, but %d", len(want), len(doc.Lines))
+	}
+	for i, line := range doc.Lines {
+		if line.FoldEnd != want[i] {
+			t.Errorf("line %d %q want fold end %d, but %d", i, line.String(), want[i], line.FoldEnd)
+		}
+	}
+}
+
+func TestRenderTable(t *testing.T) {
+	doc := renderTable([]byte("id,name\n1,alice\n22,bob\n"), ',')
+	want := []string{
+		"id | name ",
+		"---+------",
+		"1  | alice",
+		"22 | bob  ",
+	}
+	if doc.Sticky != 2 {
+		t.Errorf("want sticky 2, but %d", doc.Sticky)
+	}
+	if len(doc.Lines) != len(want) {
+		t.Fatalf("want %d lines, but %d", len(want), len(doc.Lines))
+	}
+	for i, line := range doc.Lines {
+		if line.String() != want[i] {
+			t.Errorf("line %d want %q, but %q", i, want[i], line.String())
+		}
+	}
+}
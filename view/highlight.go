@@ -0,0 +1,212 @@
+package view
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	termbox "github.com/nsf/termbox-go"
+)
+
+type language struct {
+	keywords     []string
+	lineComments []string
+	blockComment [2]string
+	quotes       string
+	ignoreCase   bool
+}
+
+var languages = map[string]*language{
+	"go": {
+		keywords: []string{
+			"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough",
+			"for", "func", "go", "goto", "if", "import", "interface", "map", "package", "range",
+			"return", "select", "struct", "switch", "type", "var", "nil", "true", "false",
+		},
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+	},
+	"python": {
+		keywords: []string{
+			"and", "as", "assert", "break", "class", "continue", "def", "del", "elif", "else",
+			"except", "finally", "for", "from", "global", "if", "import", "in", "is", "lambda",
+			"not", "or", "pass", "raise", "return", "try", "while", "with", "yield", "None", "True", "False",
+		},
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	},
+	"javascript": {
+		keywords: []string{
+			"async", "await", "break", "case", "catch", "class", "const", "continue", "default",
+			"delete", "else", "export", "extends", "finally", "for", "function", "if", "import",
+			"in", "instanceof", "let", "new", "return", "switch", "this", "throw", "try", "typeof",
+			"var", "while", "null", "undefined", "true", "false",
+		},
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+	},
+	"java": {
+		keywords: []string{
+			"abstract", "break", "case", "catch", "class", "continue", "default", "else", "extends",
+			"final", "finally", "for", "if", "implements", "import", "interface", "new", "package",
+			"private", "protected", "public", "return", "static", "switch", "this", "throw", "throws",
+			"try", "void", "while", "null", "true", "false",
+		},
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+	},
+	"ruby": {
+		keywords: []string{
+			"begin", "class", "def", "do", "else", "elsif", "end", "ensure", "if", "module", "nil",
+			"require", "rescue", "return", "self", "then", "unless", "until", "when", "while", "yield",
+			"true", "false",
+		},
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	},
+	"shell": {
+		keywords: []string{
+			"case", "do", "done", "elif", "else", "esac", "export", "fi", "for", "function", "if",
+			"in", "local", "return", "then", "until", "while",
+		},
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	},
+	"hcl": {
+		keywords: []string{
+			"data", "locals", "module", "output", "provider", "resource", "terraform", "variable",
+			"for", "in", "if", "null", "true", "false",
+		},
+		lineComments: []string{"#", "//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"",
+	},
+	"sql": {
+		keywords: []string{
+			"select", "from", "where", "and", "or", "not", "insert", "into", "values", "update",
+			"set", "delete", "create", "table", "drop", "alter", "join", "left", "right", "inner",
+			"outer", "on", "group", "by", "order", "having", "limit", "as", "null", "is", "in",
+		},
+		lineComments: []string{"--"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "'\"",
+		ignoreCase:   true,
+	},
+	"c": {
+		keywords: []string{
+			"break", "case", "char", "const", "continue", "default", "do", "double", "else", "enum",
+			"extern", "float", "for", "goto", "if", "int", "long", "return", "short", "signed",
+			"sizeof", "static", "struct", "switch", "typedef", "union", "unsigned", "void", "while",
+		},
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+	},
+	"rust": {
+		keywords: []string{
+			"as", "break", "const", "continue", "crate", "else", "enum", "fn", "for", "if", "impl",
+			"in", "let", "loop", "match", "mod", "mut", "pub", "ref", "return", "self", "static",
+			"struct", "trait", "type", "use", "where", "while", "true", "false",
+		},
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"",
+	},
+}
+
+func (lang *language) isKeyword(word string) bool {
+	for _, keyword := range lang.keywords {
+		if keyword == word || (lang.ignoreCase && strings.EqualFold(keyword, word)) {
+			return true
+		}
+	}
+	return false
+}
+
+func renderSource(body []byte, langName string) *Document {
+	lang, ok := languages[langName]
+	if !ok {
+		return renderText(body)
+	}
+
+	doc := &Document{}
+	inBlockComment := false
+	for i, text := range splitLines(body) {
+		var line *Line
+		line, inBlockComment = highlightSource(lang, text, inBlockComment)
+		doc.Lines = append(doc.Lines, numberedLine(i+1, line))
+	}
+	return doc
+}
+
+// highlightSource splits a source line into colored tokens.
+// inBlockComment carries block comment state across lines.
+func highlightSource(lang *language, text string, inBlockComment bool) (*Line, bool) {
+	line := newLine()
+	rest := text
+	add := func(n int, fg termbox.Attribute) {
+		line.Spans = append(line.Spans, Span{Text: rest[:n], FG: fg})
+		rest = rest[n:]
+	}
+
+	for len(rest) > 0 {
+		if inBlockComment {
+			end := strings.Index(rest, lang.blockComment[1])
+			if end < 0 {
+				add(len(rest), colorComment)
+				break
+			}
+			add(end+len(lang.blockComment[1]), colorComment)
+			inBlockComment = false
+			continue
+		}
+		if lang.blockComment[0] != "" && strings.HasPrefix(rest, lang.blockComment[0]) {
+			inBlockComment = true
+			add(len(lang.blockComment[0]), colorComment)
+			continue
+		}
+		if hasAnyPrefix(rest, lang.lineComments) {
+			add(len(rest), colorComment)
+			break
+		}
+
+		c, size := utf8.DecodeRuneInString(rest)
+		switch {
+		case strings.ContainsRune(lang.quotes, c):
+			add(closingQuote(rest), colorString)
+		case unicode.IsDigit(c):
+			add(wordEnd(rest), colorNumber)
+		case unicode.IsLetter(c) || c == '_':
+			n := wordEnd(rest)
+			fg := termbox.ColorDefault
+			if lang.isKeyword(rest[:n]) {
+				fg = colorKeyword
+			}
+			add(n, fg)
+		default:
+			add(size, termbox.ColorDefault)
+		}
+	}
+	return line, inBlockComment
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func wordEnd(s string) int {
+	for i, c := range s {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '.' {
+			return i
+		}
+	}
+	return len(s)
+}
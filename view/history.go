@@ -0,0 +1,90 @@
+package view
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lighttiger2505/s3tf/model"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// HistoryView lists visited locations by recent or by frecency.
+type HistoryView struct {
+	Render
+	Layer   *Layer
+	Objects []*model.Visit
+	Ranked  bool
+}
+
+func NewHistoryView(x, y, width, height int) *HistoryView {
+	return &HistoryView{
+		Layer: NewLayer(x, y, width, height),
+	}
+}
+
+func (v *HistoryView) getContents() []string {
+	drawLines := []string{}
+	for _, visit := range v.Objects {
+		line := fmt.Sprintf("%s %4d %s", visit.Time.Local().Format(time.RFC3339), visit.Count, visit.Location.String())
+		if visit.Profile != "" && !visit.Local {
+			line += " (" + visit.Profile + ")"
+		}
+		drawLines = append(drawLines, line)
+	}
+	return drawLines
+}
+
+// GetCursorObject returns visit on the cursor, nil when there is none.
+func (v *HistoryView) GetCursorObject() *model.Visit {
+	if len(v.Objects) == 0 {
+		return nil
+	}
+	return v.Objects[v.Layer.cursorPos.Y]
+}
+
+// Update sets visits keeping the cursor in them.
+func (v *HistoryView) Update(objects []*model.Visit) {
+	v.Objects = objects
+	if v.Layer.cursorPos.Y >= len(objects) {
+		v.Layer.cursorPos.Y = len(objects) - 1
+	}
+	if v.Layer.cursorPos.Y < 0 {
+		v.Layer.cursorPos.Y = 0
+	}
+	v.Layer.keepCursorVisible()
+}
+
+func (v *HistoryView) Up() int {
+	return v.Layer.UpCursor(1)
+}
+
+func (v *HistoryView) Down() int {
+	if len(v.Objects) == 0 {
+		return 0
+	}
+	return v.Layer.DownCursor(1, len(v.Objects))
+}
+
+func (v *HistoryView) HalfPageUp() int {
+	return v.Layer.HalfPageUpCursor()
+}
+
+func (v *HistoryView) HalfPageDown() int {
+	if len(v.Objects) == 0 {
+		return 0
+	}
+	return v.Layer.HalfPageDownCursor(len(v.Objects))
+}
+
+func (v *HistoryView) Draw() {
+	v.Layer.DrawBackGround(termbox.ColorDefault, termbox.ColorDefault)
+
+	lines := v.getContents()
+	v.Layer.DrawContents(
+		lines,
+		termbox.ColorWhite,
+		termbox.ColorGreen,
+		termbox.ColorDefault,
+		termbox.ColorDefault,
+	)
+}
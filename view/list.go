@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/lighttiger2505/s3tf/model"
+	runewidth "github.com/mattn/go-runewidth"
 	termbox "github.com/nsf/termbox-go"
 )
 
@@ -13,6 +14,12 @@ type ListView struct {
 	listType model.S3ListType
 	Objects  []*model.S3Object
 	Layer    *Layer
+	// marked is names of objects selected for bulk action.
+	marked map[string]bool
+	// Usages is calculated size of directories by name.
+	Usages map[string]*model.DirUsage
+	// Err is shown instead of objects when listing failed.
+	Err error
 }
 
 func NewListView(x, y, width, height int) *ListView {
@@ -22,19 +29,37 @@ func NewListView(x, y, width, height int) *ListView {
 }
 
 func (v *ListView) Draw() {
+	if v.Err != nil {
+		msg := runewidth.Truncate(v.Err.Error(), v.Layer.win.Box.Width, "~")
+		tbPrint(v.Layer.win.DrawX(0), v.Layer.win.DrawY(0), termbox.ColorRed, termbox.ColorDefault, msg)
+		return
+	}
 	for i, obj := range v.Objects {
 		drawStr := obj.Name
 		if v.listType == model.ObjectList {
 			drawStr = strings.TrimPrefix(obj.Name, v.Key)
 		}
+		if usage, ok := v.Usages[obj.Name]; ok {
+			drawStr += " (" + usage.String() + ")"
+		}
 
 		if i >= v.Layer.drawPos.Y {
 			drawY := v.Layer.getDrawY(i)
+			if drawY >= v.Layer.win.DrawY(v.Layer.win.Box.Height) {
+				break
+			}
+			drawStr = runewidth.Truncate(drawStr, v.Layer.win.Box.Width, "~")
 			var fg, bg termbox.Attribute
 			if drawY == v.Layer.getCursorY() {
 				drawStr = PadRight(drawStr, v.Layer.win.Box.Width, " ")
 				fg = termbox.ColorWhite
 				bg = termbox.ColorGreen
+				if v.marked[obj.Name] {
+					fg = termbox.ColorYellow | termbox.AttrBold
+				}
+			} else if v.marked[obj.Name] {
+				fg = termbox.ColorYellow
+				bg = termbox.ColorDefault
 			} else if model.Bucket == obj.ObjType || model.PreDir == obj.ObjType || model.Dir == obj.ObjType {
 				fg = termbox.ColorGreen
 				bg = termbox.ColorDefault
@@ -42,22 +67,63 @@ func (v *ListView) Draw() {
 				fg = termbox.ColorDefault
 				bg = termbox.ColorDefault
 			}
-			tbPrint(0, drawY, fg, bg, drawStr)
+			tbPrint(v.Layer.win.DrawX(0), drawY, fg, bg, drawStr)
 		}
 	}
 }
 
+// Cursor returns position of the cursor in Objects.
+func (v *ListView) Cursor() int {
+	return v.Layer.cursorPos.Y
+}
+
 func (v *ListView) GetCursorObject() *model.S3Object {
 	return v.Objects[v.Layer.cursorPos.Y]
 }
 
 func (v *ListView) UpdateList(node *model.Node) {
+	if v.Key != node.Key {
+		v.ClearMarks()
+	}
 	v.Layer.cursorPos.Y = node.Position
+	v.Layer.keepCursorVisible()
+	v.Err = nil
 	v.Objects = node.Objects
 	v.Key = node.Key
 	v.listType = node.GetType()
 }
 
+// ToggleMark selects or unselects cursor object for bulk action.
+func (v *ListView) ToggleMark() {
+	obj := v.GetCursorObject()
+	if obj.ObjType == model.PreDir {
+		return
+	}
+	if v.marked == nil {
+		v.marked = map[string]bool{}
+	}
+	if v.marked[obj.Name] {
+		delete(v.marked, obj.Name)
+		return
+	}
+	v.marked[obj.Name] = true
+}
+
+// MarkedObjects returns selected objects in the order of listing.
+func (v *ListView) MarkedObjects() []*model.S3Object {
+	var objects []*model.S3Object
+	for _, obj := range v.Objects {
+		if v.marked[obj.Name] {
+			objects = append(objects, obj)
+		}
+	}
+	return objects
+}
+
+func (v *ListView) ClearMarks() {
+	v.marked = nil
+}
+
 func (v *ListView) Up() int {
 	return v.Layer.UpCursor(1)
 }
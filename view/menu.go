@@ -12,6 +12,10 @@ const (
 	CommandDownload MenuCommand = iota //0
 	CommandOpen
 	CommandEdit
+	CommandView
+	CommandNewFile
+	CommandMakeDir
+	CommandProperties
 )
 
 type MenuItem struct {
@@ -48,6 +52,10 @@ func NewMenuView(x, y, width, height int) *MenuView {
 		NewMenuItem("download", "w", "download file.", CommandDownload),
 		NewMenuItem("open", "o", "open file.", CommandOpen),
 		NewMenuItem("edit", "e", "open editor by file.", CommandEdit),
+		NewMenuItem("view", "v", "view file contents.", CommandView),
+		NewMenuItem("new file", "N", "create file by editor.", CommandNewFile),
+		NewMenuItem("mkdir", "+", "create directory.", CommandMakeDir),
+		NewMenuItem("properties", "E", "edit metadata and tags by editor.", CommandProperties),
 	}
 	return view
 }
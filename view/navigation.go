@@ -3,15 +3,22 @@ package view
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/lighttiger2505/s3tf/model"
+	runewidth "github.com/mattn/go-runewidth"
 	termbox "github.com/nsf/termbox-go"
 )
 
 type NavigationView struct {
 	Render
 	currentPath string
-	Win         *Window
+	Inactive    bool
+	// Stale shows that the listing is older than cache TTL and being revalidated.
+	Stale bool
+	// CachedAt is when the listing served from disk cache was listed.
+	CachedAt time.Time
+	Win      *Window
 }
 
 func NewNavigationView(x, y, width, height int) *NavigationView {
@@ -34,7 +41,25 @@ func (v *NavigationView) SetCurrentPath(bucket string, node *model.Node) {
 	}
 }
 
+func (v *NavigationView) SetLocalPath(node *model.Node) {
+	if node.IsRoot() {
+		v.currentPath = "local"
+		return
+	}
+	v.currentPath = model.LocalPath(model.LocalRoot, node.Prefix())
+}
+
 func (v *NavigationView) Draw() {
-	str := PadRight(v.currentPath, v.Win.Box.Width, " ")
-	tbPrint(0, v.Win.DrawY(0), termbox.ColorWhite, termbox.ColorBlue, str)
+	path := v.currentPath
+	if !v.CachedAt.IsZero() {
+		path += " (cached " + v.CachedAt.Local().Format("2006-01-02 15:04") + ")"
+	} else if v.Stale {
+		path += " (stale)"
+	}
+	str := PadRight(runewidth.Truncate(path, v.Win.Box.Width, "~"), v.Win.Box.Width, " ")
+	bg := termbox.ColorBlue
+	if v.Inactive {
+		bg = termbox.ColorDefault
+	}
+	tbPrint(v.Win.DrawX(0), v.Win.DrawY(0), termbox.ColorWhite, bg, str)
 }
@@ -0,0 +1,143 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lighttiger2505/s3tf/model"
+	runewidth "github.com/mattn/go-runewidth"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// usageBarWidth is width of bar graph of UsageView.
+const usageBarWidth = 20
+
+// UsageView shows children of a usage tree node sorted by size like ncdu.
+type UsageView struct {
+	Render
+	Current *model.UsageNode
+	Layer   *Layer
+}
+
+func NewUsageView(x, y, width, height int) *UsageView {
+	return &UsageView{
+		Layer: NewLayer(x, y, width, height),
+	}
+}
+
+// SetRoot shows children of root.
+func (v *UsageView) SetRoot(root *model.UsageNode) {
+	v.Current = root
+	v.Layer.cursorPos.Y = 0
+	v.Layer.drawPos.Y = 0
+}
+
+func (v *UsageView) children() []*model.UsageNode {
+	if v.Current == nil {
+		return nil
+	}
+	return v.Current.Children
+}
+
+func (v *UsageView) getContents() []string {
+	drawLines := []string{}
+	for _, child := range v.children() {
+		ratio := child.Ratio()
+		filled := int(ratio*usageBarWidth + 0.5)
+		bar := strings.Repeat("#", filled) + strings.Repeat(" ", usageBarWidth-filled)
+		name := strings.TrimPrefix(child.Name, v.Current.Name)
+		line := fmt.Sprintf("%10s %5.1f%% [%s] %s", model.FormatBytes(child.Bytes), ratio*100, bar, name)
+		if child.Dir {
+			line += fmt.Sprintf(" (%d objects)", child.Count)
+		}
+		drawLines = append(drawLines, line)
+	}
+	return drawLines
+}
+
+// GetCursorNode returns child on the cursor, nil when there is none.
+func (v *UsageView) GetCursorNode() *model.UsageNode {
+	children := v.children()
+	if len(children) == 0 {
+		return nil
+	}
+	if v.Layer.cursorPos.Y >= len(children) {
+		v.Layer.cursorPos.Y = len(children) - 1
+	}
+	return children[v.Layer.cursorPos.Y]
+}
+
+// Enter shows children of the directory on the cursor.
+func (v *UsageView) Enter() bool {
+	node := v.GetCursorNode()
+	if node == nil || !node.Dir {
+		return false
+	}
+	v.SetRoot(node)
+	return true
+}
+
+// Leave shows the parent, with cursor on the directory left.
+func (v *UsageView) Leave() bool {
+	if v.Current == nil || v.Current.Parent == nil {
+		return false
+	}
+	left := v.Current
+	v.SetRoot(left.Parent)
+	for i, child := range v.Current.Children {
+		if child == left {
+			v.Layer.cursorPos.Y = i
+		}
+	}
+	v.Layer.keepCursorVisible()
+	return true
+}
+
+// Removed keeps the cursor in children after the one on the cursor is removed.
+func (v *UsageView) Removed() {
+	v.GetCursorNode()
+	if v.Layer.cursorPos.Y < 0 {
+		v.Layer.cursorPos.Y = 0
+	}
+	v.Layer.keepCursorVisible()
+}
+
+func (v *UsageView) Up() int {
+	return v.Layer.UpCursor(1)
+}
+
+func (v *UsageView) Down() int {
+	if len(v.children()) == 0 {
+		return 0
+	}
+	return v.Layer.DownCursor(1, len(v.children()))
+}
+
+func (v *UsageView) HalfPageUp() int {
+	return v.Layer.HalfPageUpCursor()
+}
+
+func (v *UsageView) HalfPageDown() int {
+	if len(v.children()) == 0 {
+		return 0
+	}
+	return v.Layer.HalfPageDownCursor(len(v.children()))
+}
+
+func (v *UsageView) Draw() {
+	v.Layer.DrawBackGround(termbox.ColorDefault, termbox.ColorDefault)
+	if v.Current == nil {
+		tbPrint(v.Layer.win.DrawX(0), v.Layer.win.DrawY(0), termbox.ColorDefault, termbox.ColorDefault,
+			runewidth.Truncate("scanning...", v.Layer.win.Box.Width, "~"))
+		return
+	}
+
+	lines := v.getContents()
+	v.Layer.DrawContents(
+		lines,
+		termbox.ColorWhite,
+		termbox.ColorGreen,
+		termbox.ColorDefault,
+		termbox.ColorDefault,
+	)
+}
@@ -0,0 +1,223 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lighttiger2505/s3tf/model"
+	runewidth "github.com/mattn/go-runewidth"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// PagerView shows a large object line by line reading it by byte offset.
+// Backward is direction of the last search, which n repeats and N reverses like less.
+type PagerView struct {
+	Render
+	Pager    *model.Pager
+	Key      string
+	Top      int64
+	Wrap     bool
+	Follow   bool
+	Query    string
+	Backward bool
+	Err      error
+	Layer    *Layer
+}
+
+func NewPagerView(x, y, width, height int) *PagerView {
+	return &PagerView{
+		Layer: NewLayer(x, y, width, height),
+	}
+}
+
+func (v *PagerView) SetPager(key string, pager *model.Pager, err error) {
+	v.Key = key
+	v.Pager = pager
+	v.Err = err
+	v.Top = 0
+	v.Follow = false
+	v.Layer.drawPos.X = 0
+}
+
+// Status returns position summary of pager for status line.
+func (v *PagerView) Status() string {
+	if v.Pager == nil {
+		return v.Key
+	}
+	size := v.Pager.Size()
+	percent := int64(100)
+	if size > 0 {
+		percent = v.Top * 100 / size
+	}
+	status := fmt.Sprintf("%s  %d/%d B (%d%%)", v.Key, v.Top, size, percent)
+	if v.Wrap {
+		status += " [wrap]"
+	}
+	if v.Follow {
+		status += " [follow]"
+	}
+	return status
+}
+
+func (v *PagerView) Down(n int) {
+	if v.Pager == nil {
+		return
+	}
+	for i := 0; i < n; i++ {
+		_, next, err := v.Pager.ReadLine(v.Top)
+		if err != nil {
+			v.Err = err
+			return
+		}
+		if next >= v.Pager.Size() {
+			return
+		}
+		v.Top = next
+	}
+}
+
+func (v *PagerView) Up(n int) {
+	if v.Pager == nil {
+		return
+	}
+	for i := 0; i < n && v.Top > 0; i++ {
+		prev, err := v.Pager.PrevLine(v.Top)
+		if err != nil {
+			v.Err = err
+			return
+		}
+		v.Top = prev
+	}
+}
+
+func (v *PagerView) HalfPageDown() {
+	v.Down(v.Layer.win.Box.Height / 2)
+}
+
+func (v *PagerView) HalfPageUp() {
+	v.Up(v.Layer.win.Box.Height / 2)
+}
+
+func (v *PagerView) Head() {
+	v.Top = 0
+}
+
+// Tail scrolls so that the last line is shown at the bottom of view.
+func (v *PagerView) Tail() {
+	if v.Pager == nil {
+		return
+	}
+	last, err := v.Pager.LastLine()
+	if err != nil {
+		v.Err = err
+		return
+	}
+	v.Top = last
+	v.Up(v.Layer.win.Box.Height - 1)
+}
+
+// JumpTo shows the line containing offset at the top of view.
+func (v *PagerView) JumpTo(off int64) {
+	if v.Pager == nil {
+		return
+	}
+	if size := v.Pager.Size(); off >= size {
+		off = size - 1
+	}
+	start, err := v.Pager.LineStart(off)
+	if err != nil {
+		v.Err = err
+		return
+	}
+	v.Top = start
+}
+
+func (v *PagerView) Left() int {
+	return v.Layer.ScrollLeft(horizontalScrollWidth)
+}
+
+func (v *PagerView) Right() int {
+	return v.Layer.ScrollRight(horizontalScrollWidth)
+}
+
+// rows splits line into rows of view width when wrapping.
+func (v *PagerView) rows(text string) []string {
+	width := v.Layer.win.Box.Width
+	if !v.Wrap || width <= 0 || runewidth.StringWidth(text) <= width {
+		return []string{text}
+	}
+	rows := []string{}
+	var row strings.Builder
+	w := 0
+	for _, c := range text {
+		cw := runewidth.RuneWidth(c)
+		if w+cw > width {
+			rows = append(rows, row.String())
+			row.Reset()
+			w = 0
+		}
+		row.WriteRune(c)
+		w += cw
+	}
+	return append(rows, row.String())
+}
+
+// highlight marks matches of search query in text.
+func (v *PagerView) highlight(text string) *Line {
+	line := newLine()
+	if v.Query == "" {
+		line.Spans = append(line.Spans, Span{Text: text})
+		return line
+	}
+	for {
+		i := strings.Index(text, v.Query)
+		if i < 0 {
+			break
+		}
+		line.Spans = append(line.Spans,
+			Span{Text: text[:i]},
+			Span{Text: v.Query, FG: termbox.AttrReverse},
+		)
+		text = text[i+len(v.Query):]
+	}
+	line.Spans = append(line.Spans, Span{Text: text})
+	return line
+}
+
+func (v *PagerView) Draw() {
+	v.Layer.DrawBackGround(termbox.ColorDefault, termbox.ColorDefault)
+	if v.Err != nil {
+		v.Layer.DrawLine(0, plainLine(v.Err.Error()), termbox.ColorDefault, termbox.ColorDefault)
+		return
+	}
+	if v.Pager == nil {
+		v.Layer.DrawLine(0, plainLine("loading..."), termbox.ColorDefault, termbox.ColorDefault)
+		return
+	}
+
+	if v.Wrap {
+		v.Layer.drawPos.X = 0
+	}
+	off := v.Top
+	size := v.Pager.Size()
+	for y := 0; y < v.Layer.win.Box.Height && off < size; {
+		text, next, err := v.Pager.ReadLine(off)
+		if err != nil {
+			v.Err = err
+			return
+		}
+		if !utf8.ValidString(text) {
+			text = strings.ToValidUTF8(text, "?")
+		}
+		text = strings.Replace(text, "\t", "    ", -1)
+		for _, row := range v.rows(text) {
+			if y >= v.Layer.win.Box.Height {
+				break
+			}
+			v.Layer.DrawLine(y, v.highlight(row), termbox.ColorDefault, termbox.ColorDefault)
+			y++
+		}
+		off = next
+	}
+}
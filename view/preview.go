@@ -0,0 +1,87 @@
+package view
+
+import (
+	"fmt"
+
+	"github.com/lighttiger2505/s3tf/model"
+	termbox "github.com/nsf/termbox-go"
+)
+
+type PreviewView struct {
+	Render
+	Preview *model.Preview
+	Err     error
+	doc     *Document
+	Layer   *Layer
+}
+
+func NewPreviewView(x, y, width, height int) *PreviewView {
+	return &PreviewView{
+		Layer: NewLayer(x, y, width, height),
+	}
+}
+
+func (v *PreviewView) SetPreview(preview *model.Preview, err error) {
+	v.Preview = preview
+	v.Err = err
+	v.doc = renderPreviewDocument(preview, err)
+	v.Layer.cursorPos.Y = 0
+	v.Layer.drawPos.Y = 0
+	v.Layer.drawPos.X = 0
+}
+
+// renderPreviewDocument renders preview with a header line of object summary.
+func renderPreviewDocument(pv *model.Preview, err error) *Document {
+	if err != nil {
+		return &Document{Lines: []*Line{plainLine(err.Error())}}
+	}
+	if pv == nil {
+		return &Document{Lines: []*Line{plainLine("loading...")}}
+	}
+
+	switch pv.ObjType {
+	case model.Bucket, model.Dir:
+		return &Document{Lines: []*Line{
+			plainLine(pv.Key),
+			plainLine(""),
+			plainLine(fmt.Sprintf("    Dirs: %d", pv.Dirs)),
+			plainLine(fmt.Sprintf("    Objects: %d", pv.Objects)),
+			plainLine(fmt.Sprintf("    Size: %d B", pv.Size)),
+		}}
+	case model.Object:
+		header := fmt.Sprintf("%s (%d B, %s)", pv.Key, pv.Size, pv.Format)
+		if pv.Encoding != "" {
+			header = fmt.Sprintf("%s (%d B, %s, %s)", pv.Key, pv.Size, pv.Format, pv.Encoding)
+		}
+		doc := &Document{
+			Lines: append([]*Line{newLine(Span{Text: header, FG: colorHeader})}, RenderPreview(pv).Lines...),
+		}
+		if pv.Truncated {
+			doc.Lines = append(doc.Lines, plainLine(""), plainLine(fmt.Sprintf("-- first %d KB of object --", pv.Fetched/1024)))
+		}
+		return doc
+	}
+	return &Document{}
+}
+
+func (v *PreviewView) Up() int {
+	return v.Layer.ScrollUp(1)
+}
+
+func (v *PreviewView) Down() int {
+	return v.Layer.ScrollDown(1, len(v.doc.Lines))
+}
+
+func (v *PreviewView) Draw() {
+	v.Layer.DrawBackGround(termbox.ColorDefault, termbox.ColorDefault)
+	if v.doc == nil {
+		return
+	}
+	for y := 0; y < v.Layer.win.Box.Height; y++ {
+		i := v.Layer.drawPos.Y + y
+		if i >= len(v.doc.Lines) {
+			break
+		}
+		v.Layer.DrawLine(y, v.doc.Lines[i], termbox.ColorDefault, termbox.ColorDefault)
+	}
+}
@@ -0,0 +1,68 @@
+package view
+
+import (
+	"strings"
+
+	runewidth "github.com/mattn/go-runewidth"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// PromptView is one line text input shown on the status line.
+type PromptView struct {
+	Render
+	Label string
+	// Candidates of completion are shown above the prompt.
+	Candidates []string
+	text       []rune
+	Win        *Window
+}
+
+func NewPromptView(x, y, width, height int) *PromptView {
+	return &PromptView{
+		Win: newWindow(x, y, width, height),
+	}
+}
+
+func (v *PromptView) Reset(label, text string) {
+	v.Label = label
+	v.text = []rune(text)
+	v.Candidates = nil
+}
+
+func (v *PromptView) Value() string {
+	return string(v.text)
+}
+
+func (v *PromptView) SetValue(text string) {
+	v.text = []rune(text)
+}
+
+// Input edits text by key event. It returns false for keys not used for editing.
+func (v *PromptView) Input(ev termbox.Event) bool {
+	switch {
+	case ev.Key == termbox.KeyBackspace || ev.Key == termbox.KeyBackspace2:
+		if len(v.text) > 0 {
+			v.text = v.text[:len(v.text)-1]
+		}
+	case ev.Key == termbox.KeyCtrlW || ev.Key == termbox.KeyCtrlU:
+		v.text = []rune{}
+	case ev.Key == termbox.KeySpace:
+		v.text = append(v.text, ' ')
+	case ev.Ch != 0:
+		v.text = append(v.text, ev.Ch)
+	default:
+		return false
+	}
+	v.Candidates = nil
+	return true
+}
+
+func (v *PromptView) Draw() {
+	if len(v.Candidates) > 0 && v.Win.Pos.Y > 0 {
+		line := runewidth.Truncate(strings.Join(v.Candidates, "  "), v.Win.Box.Width, "~")
+		tbPrint(0, v.Win.DrawY(-1), termbox.ColorYellow, termbox.ColorDefault, PadRight(line, v.Win.Box.Width, " "))
+	}
+	str := v.Label + string(v.text)
+	tbPrint(0, v.Win.DrawY(0), termbox.ColorDefault, termbox.ColorDefault, PadRight(str, v.Win.Box.Width, " "))
+	termbox.SetCursor(len([]rune(str)), v.Win.DrawY(0))
+}
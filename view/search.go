@@ -0,0 +1,90 @@
+package view
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/lighttiger2505/s3tf/model"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// SearchView lists objects found by recursive search with full keys.
+type SearchView struct {
+	Render
+	Layer   *Layer
+	Objects []*model.S3Object
+}
+
+func NewSearchView(x, y, width, height int) *SearchView {
+	return &SearchView{
+		Layer: NewLayer(x, y, width, height),
+	}
+}
+
+func (v *SearchView) getContents() []string {
+	drawLines := []string{}
+	for _, obj := range v.Objects {
+		modified := ""
+		if obj.Date != nil {
+			modified = obj.Date.Local().Format(time.RFC3339)
+		}
+		drawLines = append(drawLines, fmt.Sprintf("%-25s %10s %s", modified, model.FormatBytes(aws.Int64Value(obj.Size)), obj.Name))
+	}
+	return drawLines
+}
+
+// GetCursorObject returns object on the cursor, nil when there is none.
+func (v *SearchView) GetCursorObject() *model.S3Object {
+	if len(v.Objects) == 0 {
+		return nil
+	}
+	return v.Objects[v.Layer.cursorPos.Y]
+}
+
+// Reset clears results for a new search.
+func (v *SearchView) Reset() {
+	v.Objects = nil
+	v.Layer.cursorPos.Y = 0
+	v.Layer.drawPos.Y = 0
+}
+
+// Append adds results found, keeping the cursor.
+func (v *SearchView) Append(objects []*model.S3Object) {
+	v.Objects = append(v.Objects, objects...)
+}
+
+func (v *SearchView) Up() int {
+	return v.Layer.UpCursor(1)
+}
+
+func (v *SearchView) Down() int {
+	if len(v.Objects) == 0 {
+		return 0
+	}
+	return v.Layer.DownCursor(1, len(v.Objects))
+}
+
+func (v *SearchView) HalfPageUp() int {
+	return v.Layer.HalfPageUpCursor()
+}
+
+func (v *SearchView) HalfPageDown() int {
+	if len(v.Objects) == 0 {
+		return 0
+	}
+	return v.Layer.HalfPageDownCursor(len(v.Objects))
+}
+
+func (v *SearchView) Draw() {
+	v.Layer.DrawBackGround(termbox.ColorDefault, termbox.ColorDefault)
+
+	lines := v.getContents()
+	v.Layer.DrawContents(
+		lines,
+		termbox.ColorWhite,
+		termbox.ColorGreen,
+		termbox.ColorDefault,
+		termbox.ColorDefault,
+	)
+}
@@ -0,0 +1,41 @@
+package view
+
+import (
+	"fmt"
+
+	runewidth "github.com/mattn/go-runewidth"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// TabView is tab bar shown above NavigationView.
+type TabView struct {
+	Render
+	Names  []string
+	Active int
+	Win    *Window
+}
+
+func NewTabView(x, y, width, height int) *TabView {
+	return &TabView{
+		Win: newWindow(x, y, width, height),
+	}
+}
+
+func (v *TabView) Draw() {
+	x := v.Win.DrawX(0)
+	tbPrint(x, v.Win.DrawY(0), termbox.ColorDefault, termbox.ColorDefault, PadRight("", v.Win.Box.Width, " "))
+	for i, name := range v.Names {
+		label := fmt.Sprintf(" %d:%s ", i+1, name)
+		rest := v.Win.Box.Width - (x - v.Win.DrawX(0))
+		if rest <= 0 {
+			break
+		}
+		label = runewidth.Truncate(label, rest, "~")
+		fg, bg := termbox.ColorWhite, termbox.ColorDefault
+		if i == v.Active {
+			fg, bg = termbox.ColorBlack, termbox.ColorWhite
+		}
+		tbPrint(x, v.Win.DrawY(0), fg, bg, label)
+		x += runewidth.StringWidth(label)
+	}
+}
@@ -0,0 +1,30 @@
+package view
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lighttiger2505/s3tf/model"
+)
+
+// RenderUsage renders size of directory broken down by storage class.
+func RenderUsage(path string, usage *model.DirUsage) *Document {
+	doc := &Document{}
+	doc.Lines = append(doc.Lines,
+		newLine(Span{Text: path, FG: colorHeader}),
+		plainLine(""),
+		plainLine(fmt.Sprintf("    Total: %s (%d B), %d objects", model.FormatBytes(usage.Bytes), usage.Bytes, usage.Count)),
+		plainLine(fmt.Sprintf("    Calculated: %s", usage.CalculatedAt.Local().Format(time.RFC3339))),
+		plainLine(""),
+		newLine(Span{Text: fmt.Sprintf("    %-20s %12s %7s %10s", "StorageClass", "Size", "Ratio", "Objects"), FG: colorComment}),
+	)
+	for _, name := range usage.ClassNames() {
+		c := usage.Classes[name]
+		ratio := 0.0
+		if usage.Bytes > 0 {
+			ratio = float64(c.Bytes) * 100 / float64(usage.Bytes)
+		}
+		doc.Lines = append(doc.Lines, plainLine(fmt.Sprintf("    %-20s %12s %6.1f%% %10d", name, model.FormatBytes(c.Bytes), ratio, c.Count)))
+	}
+	return doc
+}
@@ -3,6 +3,7 @@ package view
 import (
 	"log"
 
+	runewidth "github.com/mattn/go-runewidth"
 	termbox "github.com/nsf/termbox-go"
 )
 
@@ -28,6 +29,16 @@ func (l *Layer) Resize(x, y, width, height int) {
 	l.win.Resize(x, y, width, height)
 }
 
+// keepCursorVisible scrolls draw position to show the cursor.
+func (l *Layer) keepCursorVisible() {
+	if l.cursorPos.Y < l.drawPos.Y {
+		l.drawPos.Y = l.cursorPos.Y
+	}
+	if l.cursorPos.Y > l.drawPos.Y+l.win.Box.Height-1 {
+		l.drawPos.Y = l.cursorPos.Y - l.win.Box.Height + 1
+	}
+}
+
 func (l *Layer) getCursorY() int {
 	return l.win.DrawY(l.cursorPos.Y) - l.drawPos.Y
 }
@@ -55,6 +66,9 @@ func (l *Layer) DrawContents(
 			drawStr := PadRight(line, l.win.Box.Width, " ")
 			drawX := l.win.DrawX(0)
 			drawY := l.getDrawY(i)
+			if drawY >= l.win.DrawY(l.win.Box.Height) {
+				break
+			}
 			var fg, bg termbox.Attribute
 			if drawY == l.getCursorY() {
 				fg = cursorFG
@@ -68,6 +82,39 @@ func (l *Layer) DrawContents(
 	}
 }
 
+// DrawLine draws styled line on row y of layer, scrolled by horizontal draw position and clipped to layer width.
+func (l *Layer) DrawLine(y int, line *Line, attr, bg termbox.Attribute) {
+	drawY := l.win.DrawY(y)
+	x := -l.drawPos.X
+	for _, span := range line.Spans {
+		for _, c := range span.Text {
+			w := runewidth.RuneWidth(c)
+			if x >= 0 && x+w <= l.win.Box.Width {
+				termbox.SetCell(l.win.DrawX(x), drawY, c, span.FG|attr, bg)
+			}
+			x += w
+		}
+	}
+	for ; x < l.win.Box.Width; x++ {
+		if x >= 0 {
+			termbox.SetCell(l.win.DrawX(x), drawY, ' ', attr, bg)
+		}
+	}
+}
+
+func (l *Layer) ScrollLeft(val int) int {
+	l.drawPos.X -= val
+	if l.drawPos.X < 0 {
+		l.drawPos.X = 0
+	}
+	return l.drawPos.X
+}
+
+func (l *Layer) ScrollRight(val int) int {
+	l.drawPos.X += val
+	return l.drawPos.X
+}
+
 func (l *Layer) UpCursor(val int) int {
 	l.cursorPos.Y -= val
 	if l.cursorPos.Y < 0 {
@@ -92,6 +139,28 @@ func (l *Layer) DownCursor(val int, contentNum int) int {
 	return l.cursorPos.Y
 }
 
+func (l *Layer) ScrollUp(val int) int {
+	l.drawPos.Y -= val
+	if l.drawPos.Y < 0 {
+		l.drawPos.Y = 0
+	}
+	l.cursorPos.Y = l.drawPos.Y
+	return l.drawPos.Y
+}
+
+func (l *Layer) ScrollDown(val int, contentNum int) int {
+	maxPos := contentNum - l.win.Box.Height
+	if maxPos < 0 {
+		maxPos = 0
+	}
+	l.drawPos.Y += val
+	if l.drawPos.Y > maxPos {
+		l.drawPos.Y = maxPos
+	}
+	l.cursorPos.Y = l.drawPos.Y
+	return l.drawPos.Y
+}
+
 func (l *Layer) HalfPageUpCursor() int {
 	_, height := termbox.Size()
 	halfPage := height / 2
@@ -0,0 +1,156 @@
+package view
+
+import (
+	"fmt"
+
+	"github.com/lighttiger2505/s3tf/model"
+	termbox "github.com/nsf/termbox-go"
+)
+
+const horizontalScrollWidth = 8
+
+// ViewerView shows whole object rendered by its format, with folding of JSON/YAML blocks.
+type ViewerView struct {
+	Render
+	Preview *model.Preview
+	Err     error
+	doc     *Document
+	folded  map[int]bool
+	body    *Layer
+	Layer   *Layer
+}
+
+func NewViewerView(x, y, width, height int) *ViewerView {
+	return &ViewerView{
+		Layer:  NewLayer(x, y, width, height),
+		body:   NewLayer(x, y, width, height),
+		folded: map[int]bool{},
+	}
+}
+
+func (v *ViewerView) SetPreview(preview *model.Preview, err error) {
+	v.Preview = preview
+	v.Err = err
+	v.folded = map[int]bool{}
+	v.body.cursorPos.Y = 0
+	v.body.drawPos.Y = 0
+	v.body.drawPos.X = 0
+
+	switch {
+	case err != nil:
+		v.doc = &Document{Lines: []*Line{plainLine(err.Error())}}
+	case preview == nil:
+		v.doc = &Document{Lines: []*Line{plainLine("loading...")}}
+	default:
+		v.doc = RenderPreview(preview)
+	}
+}
+
+// SetDocument shows document which is not object contents, e.g. diff of change.
+func (v *ViewerView) SetDocument(doc *Document) {
+	v.Preview = nil
+	v.Err = nil
+	v.folded = map[int]bool{}
+	v.body.cursorPos.Y = 0
+	v.body.drawPos.Y = 0
+	v.body.drawPos.X = 0
+	v.doc = doc
+}
+
+// Title returns summary of viewing object for status line.
+func (v *ViewerView) Title() string {
+	pv := v.Preview
+	if pv == nil {
+		return ""
+	}
+	title := fmt.Sprintf("%s [%s]", pv.Key, pv.Format)
+	if pv.Truncated {
+		title = fmt.Sprintf("%s (first %d KB of %d B)", title, pv.Fetched/1024, pv.Size)
+	}
+	return title
+}
+
+// visibleLines returns indexes of lines not hidden in folded blocks.
+func (v *ViewerView) visibleLines() []int {
+	indexes := []int{}
+	if v.doc == nil {
+		return indexes
+	}
+	for i := v.doc.Sticky; i < len(v.doc.Lines); i++ {
+		indexes = append(indexes, i)
+		if end := v.doc.Lines[i].FoldEnd; v.folded[i] && end > i {
+			i = end
+		}
+	}
+	return indexes
+}
+
+func (v *ViewerView) ToggleFold() {
+	visible := v.visibleLines()
+	if v.body.cursorPos.Y >= len(visible) {
+		return
+	}
+	i := visible[v.body.cursorPos.Y]
+	if v.doc.Lines[i].FoldEnd < 0 {
+		return
+	}
+	v.folded[i] = !v.folded[i]
+}
+
+func (v *ViewerView) Up() int {
+	return v.body.UpCursor(1)
+}
+
+func (v *ViewerView) Down() int {
+	return v.body.DownCursor(1, len(v.visibleLines()))
+}
+
+func (v *ViewerView) HalfPageUp() int {
+	return v.body.HalfPageUpCursor()
+}
+
+func (v *ViewerView) HalfPageDown() int {
+	return v.body.HalfPageDownCursor(len(v.visibleLines()))
+}
+
+func (v *ViewerView) Left() int {
+	return v.body.ScrollLeft(horizontalScrollWidth)
+}
+
+func (v *ViewerView) Right() int {
+	return v.body.ScrollRight(horizontalScrollWidth)
+}
+
+func (v *ViewerView) Draw() {
+	v.Layer.DrawBackGround(termbox.ColorDefault, termbox.ColorDefault)
+	if v.doc == nil {
+		return
+	}
+
+	win := v.Layer.win
+	sticky := v.doc.Sticky
+	v.body.Resize(win.Pos.X, win.Pos.Y+sticky, win.Box.Width, win.Box.Height-sticky)
+
+	// pinned lines such as table header share horizontal scroll with body
+	header := &Layer{win: win, cursorPos: v.body.cursorPos, drawPos: &Position{X: v.body.drawPos.X}}
+	for i := 0; i < sticky && i < len(v.doc.Lines); i++ {
+		header.DrawLine(i, v.doc.Lines[i], termbox.ColorDefault, termbox.ColorDefault)
+	}
+
+	visible := v.visibleLines()
+	for y := 0; y < v.body.win.Box.Height; y++ {
+		n := v.body.drawPos.Y + y
+		if n >= len(visible) {
+			break
+		}
+		line := v.doc.Lines[visible[n]]
+		if v.folded[visible[n]] {
+			line = &Line{Spans: append(append([]Span{}, line.Spans...), Span{Text: " ..."})}
+		}
+		attr := termbox.ColorDefault
+		if n == v.body.cursorPos.Y {
+			attr = termbox.AttrReverse
+		}
+		v.body.DrawLine(y, line, attr, termbox.ColorDefault)
+	}
+}